@@ -0,0 +1,167 @@
+// Package api defines the stable, versioned representation of flow data
+// emitted by `flow list`/`flow show` with --format=json|yaml and exchanged
+// with the HTTP server started by `flow serve`.
+//
+// Schema (version 1):
+//
+//	{
+//	  "schema_version": 1,
+//	  "tasks": [                       // "task": {...} for a single task
+//	    {
+//	      "id": 12,
+//	      "title": "Write report",
+//	      "description": "..." | null,
+//	      "status": "not_started" | "in_progress" | "completed",
+//	      "completed": false,
+//	      "priority": "A" | null,      // "A" (highest) to "Z"
+//	      "parent_id": 3 | null,
+//	      "ancestor_ids": [1, 3],      // root first, [] for root tasks
+//	      "scheduled_on": "2026-11-01" | null,
+//	      "due_date": "2026-11-01" | null,
+//	      "created_at": "2026-10-18T09:00:00Z",
+//	      "updated_at": "2026-10-18T09:00:00Z",
+//	      "version": 3,                // bumped on every change
+//	      "tags": [{"id": 1, "name": "work/clientA", "color": "39"}],
+//	      "children": [ ... ]          // tree form only
+//	    }
+//	  ]
+//	}
+//
+// Fields are only ever added within a schema version; renaming or removing a
+// field, or changing its type, increments SchemaVersion.
+package api
+
+import (
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// SchemaVersion is the version of the document layout described above.
+const SchemaVersion = 1
+
+// Tag is the exported form of a tag.
+type Tag struct {
+	ID    int    `json:"id" yaml:"id"`
+	Name  string `json:"name" yaml:"name"`
+	Color string `json:"color" yaml:"color"`
+}
+
+// Task is the exported form of a task.
+type Task struct {
+	ID          int       `json:"id" yaml:"id"`
+	Title       string    `json:"title" yaml:"title"`
+	Description *string   `json:"description" yaml:"description"`
+	Status      string    `json:"status" yaml:"status"`
+	Completed   bool      `json:"completed" yaml:"completed"`
+	Priority    *string   `json:"priority" yaml:"priority"`
+	ParentID    *int      `json:"parent_id" yaml:"parent_id"`
+	AncestorIDs []int     `json:"ancestor_ids" yaml:"ancestor_ids"`
+	ScheduledOn *string   `json:"scheduled_on" yaml:"scheduled_on"`
+	DueDate     *string   `json:"due_date" yaml:"due_date"`
+	CreatedAt   time.Time `json:"created_at" yaml:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" yaml:"updated_at"`
+	Version     int       `json:"version" yaml:"version"`
+	Tags        []Tag     `json:"tags" yaml:"tags"`
+	Children    []Task    `json:"children,omitempty" yaml:"children,omitempty"`
+}
+
+// TaskList is the document emitted for a list of tasks.
+type TaskList struct {
+	SchemaVersion int    `json:"schema_version" yaml:"schema_version"`
+	Tasks         []Task `json:"tasks" yaml:"tasks"`
+}
+
+// TaskDocument is the document emitted for a single task.
+type TaskDocument struct {
+	SchemaVersion int  `json:"schema_version" yaml:"schema_version"`
+	Task          Task `json:"task" yaml:"task"`
+}
+
+// NewTag converts a model tag.
+func NewTag(t model.Tag) Tag {
+	return Tag{ID: t.ID, Name: t.Name, Color: t.Color}
+}
+
+// NewTask converts a model task. ancestorIDs lists the task's ancestors,
+// root first.
+func NewTask(t model.Task, ancestorIDs []int) Task {
+	tags := make([]Tag, len(t.Tags))
+	for i, tag := range t.Tags {
+		tags[i] = NewTag(tag)
+	}
+	if ancestorIDs == nil {
+		ancestorIDs = []int{}
+	}
+	var priority *string
+	if p := t.PriorityLetter(); p != "" {
+		priority = &p
+	}
+	return Task{
+		ID:          t.ID,
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status.String(),
+		Completed:   t.Completed,
+		Priority:    priority,
+		ParentID:    t.ParentID,
+		AncestorIDs: ancestorIDs,
+		ScheduledOn: t.ScheduledOn,
+		DueDate:     t.DueDate,
+		CreatedAt:   t.CreatedAt,
+		UpdatedAt:   t.UpdatedAt,
+		Version:     t.Version,
+		Tags:        tags,
+	}
+}
+
+// NewTaskList converts tasks, computing ancestor IDs from all, which should
+// hold every task in the store. With tree set, tasks are nested under their
+// parents; tasks whose parent is not in the list become roots.
+func NewTaskList(tasks, all []model.Task, tree bool) TaskList {
+	parents := make(map[int]*int, len(all))
+	for _, t := range all {
+		parents[t.ID] = t.ParentID
+	}
+	ancestors := func(id int) []int {
+		var ids []int
+		for pid := parents[id]; pid != nil; pid = parents[*pid] {
+			ids = append([]int{*pid}, ids...)
+		}
+		return ids
+	}
+
+	list := TaskList{SchemaVersion: SchemaVersion, Tasks: []Task{}}
+	if !tree {
+		for _, t := range tasks {
+			list.Tasks = append(list.Tasks, NewTask(t, ancestors(t.ID)))
+		}
+		return list
+	}
+
+	children := make(map[int][]model.Task)
+	inList := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		inList[t.ID] = true
+	}
+	var roots []model.Task
+	for _, t := range tasks {
+		if t.ParentID != nil && inList[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+	var nest func(t model.Task) Task
+	nest = func(t model.Task) Task {
+		out := NewTask(t, ancestors(t.ID))
+		for _, c := range children[t.ID] {
+			out.Children = append(out.Children, nest(c))
+		}
+		return out
+	}
+	for _, r := range roots {
+		list.Tasks = append(list.Tasks, nest(r))
+	}
+	return list
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// ExportFormat identifies documents written by `flow export`.
+const ExportFormat = "flow-export"
+
+// Export is the backup document written by `flow export` and read by
+// `flow import`. Tasks are listed parents first so that they can be
+// recreated in order; IDs are only meaningful within the document.
+//
+//	{
+//	  "format": "flow-export",
+//	  "schema_version": 1,
+//	  "exported_at": "2026-10-18T09:00:00Z",
+//	  "tags": [{"id": 1, "name": "work", "color": "39", "inherited": false}],
+//	  "views": [{"name": "this week", "query": "due<=+7d"}],
+//	  "tasks": [ ... ],            // Task objects as above, without children
+//	  "dependencies": [{"task_id": 3, "depends_on_id": 2}]
+//	}
+type Export struct {
+	Format        string       `json:"format"`
+	SchemaVersion int          `json:"schema_version"`
+	ExportedAt    time.Time    `json:"exported_at"`
+	Tags          []ExportTag  `json:"tags"`
+	Views         []View       `json:"views"`
+	Tasks         []Task       `json:"tasks"`
+	Dependencies  []Dependency `json:"dependencies,omitempty"`
+}
+
+// ExportTag is a tag in an export document. Inherited tags take their color
+// from the parent tag; Color then holds the resolved value.
+type ExportTag struct {
+	Tag
+	Inherited bool `json:"inherited"`
+}
+
+// Dependency records that the task TaskID cannot start before DependsOnID
+// is done.
+type Dependency struct {
+	TaskID      int `json:"task_id"`
+	DependsOnID int `json:"depends_on_id"`
+}
+
+// View is a saved query.
+type View struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// NewExportTag converts a model tag.
+func NewExportTag(t model.Tag) ExportTag {
+	return ExportTag{Tag: NewTag(t), Inherited: t.Inherited}
+}
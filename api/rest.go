@@ -0,0 +1,105 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// TaskInput is the request body for creating a task.
+type TaskInput struct {
+	Title       string   `json:"title"`
+	Description *string  `json:"description,omitempty"`
+	Status      string   `json:"status,omitempty"`
+	ParentID    *int     `json:"parent_id,omitempty"`
+	ScheduledOn *string  `json:"scheduled_on,omitempty"`
+	DueDate     *string  `json:"due_date,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+}
+
+// TaskPatch is the request body for updating a task. Only fields that are
+// present are changed; an explicit null clears an optional field. When
+// Version is non-zero (or an If-Match header is sent) the update fails with
+// 412 Precondition Failed if the task has changed in the meantime.
+type TaskPatch struct {
+	Version     int             `json:"version,omitzero"`
+	Title       Field[string]   `json:"title,omitzero"`
+	Description Field[string]   `json:"description,omitzero"`
+	Status      Field[string]   `json:"status,omitzero"`
+	ParentID    Field[int]      `json:"parent_id,omitzero"`
+	ScheduledOn Field[string]   `json:"scheduled_on,omitzero"`
+	DueDate     Field[string]   `json:"due_date,omitzero"`
+	Tags        Field[[]string] `json:"tags,omitzero"`
+}
+
+// Field is a PATCH value that distinguishes "absent" from "null".
+type Field[T any] struct {
+	Set   bool // the field was present
+	Null  bool // the field was null
+	Value T
+}
+
+// Set returns a field carrying v.
+func Set[T any](v T) Field[T] {
+	return Field[T]{Set: true, Value: v}
+}
+
+// Null returns a field that clears the value.
+func Null[T any]() Field[T] {
+	return Field[T]{Set: true, Null: true}
+}
+
+// IsZero reports whether the field is absent, for the omitzero option.
+func (f Field[T]) IsZero() bool {
+	return !f.Set
+}
+
+func (f Field[T]) MarshalJSON() ([]byte, error) {
+	if f.Null {
+		return []byte("null"), nil
+	}
+	return json.Marshal(f.Value)
+}
+
+func (f *Field[T]) UnmarshalJSON(data []byte) error {
+	f.Set = true
+	if bytes.Equal(bytes.TrimSpace(data), []byte("null")) {
+		f.Null = true
+		return nil
+	}
+	return json.Unmarshal(data, &f.Value)
+}
+
+// StatusInput is the request body for changing a task's status.
+type StatusInput struct {
+	Status string `json:"status"`
+}
+
+// TagInput is the request body for creating or updating a tag. Empty fields
+// are left unchanged on update.
+type TagInput struct {
+	Name  string `json:"name,omitempty"`
+	Color string `json:"color,omitempty"`
+}
+
+// TagUsage is a tag together with the number of tasks carrying it.
+type TagUsage struct {
+	Tag
+	Tasks int `json:"tasks"`
+}
+
+// TagList is the document returned for the list of tags.
+type TagList struct {
+	SchemaVersion int        `json:"schema_version"`
+	Tags          []TagUsage `json:"tags"`
+}
+
+// TagDocument is the document returned for a single tag.
+type TagDocument struct {
+	SchemaVersion int `json:"schema_version"`
+	Tag           Tag `json:"tag"`
+}
+
+// Error is the body of every non-2xx response.
+type Error struct {
+	Error string `json:"error"`
+}
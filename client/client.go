@@ -0,0 +1,225 @@
+// Package client talks to the HTTP API started by `flow serve`, letting
+// other local tools read and change tasks without opening the SQLite file.
+//
+//	c := client.New("http://127.0.0.1:7070")
+//	doc, err := c.CreateTask(ctx, api.TaskInput{Title: "Write report"})
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/nissyi-gh/flow/api"
+)
+
+// Sentinel errors matched by *Error through errors.Is.
+var (
+	ErrNotFound = errors.New("not found")
+	ErrConflict = errors.New("version conflict")
+)
+
+// Error is returned for every non-2xx response.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("flow api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Is reports 404 as ErrNotFound and 412 as ErrConflict.
+func (e *Error) Is(target error) bool {
+	switch target {
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusPreconditionFailed
+	}
+	return false
+}
+
+// Client is an API client. The zero HTTPClient uses http.DefaultClient.
+type Client struct {
+	BaseURL    string
+	HTTPClient *http.Client
+}
+
+// New returns a client for the server at baseURL, such as
+// "http://127.0.0.1:7070".
+func New(baseURL string) *Client {
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// ListTasks returns the tasks matching query (see `flow help` for the query
+// language); an empty query lists everything.
+func (c *Client) ListTasks(ctx context.Context, query string) (api.TaskList, error) {
+	var list api.TaskList
+	err := c.do(ctx, http.MethodGet, "/tasks?q="+url.QueryEscape(query), "", nil, &list)
+	return list, err
+}
+
+// Tree returns every task nested under its parent.
+func (c *Client) Tree(ctx context.Context) (api.TaskList, error) {
+	var list api.TaskList
+	err := c.do(ctx, http.MethodGet, "/tree", "", nil, &list)
+	return list, err
+}
+
+// Search returns the tasks matching query as a flat list.
+func (c *Client) Search(ctx context.Context, query string) (api.TaskList, error) {
+	var list api.TaskList
+	err := c.do(ctx, http.MethodGet, "/search?q="+url.QueryEscape(query), "", nil, &list)
+	return list, err
+}
+
+// GetTask returns a single task.
+func (c *Client) GetTask(ctx context.Context, id int) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodGet, taskPath(id), "", nil, &doc)
+	return doc, err
+}
+
+// CreateTask adds a task.
+func (c *Client) CreateTask(ctx context.Context, in api.TaskInput) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodPost, "/tasks", "", in, &doc)
+	return doc, err
+}
+
+// UpdateTask applies patch to a task. When patch.Version is set the update
+// fails with ErrConflict if the task has changed since that version.
+func (c *Client) UpdateTask(ctx context.Context, id int, patch api.TaskPatch) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodPatch, taskPath(id), ifMatch(patch.Version), patch, &doc)
+	return doc, err
+}
+
+// SetStatus changes a task's status ("not_started", "in_progress" or
+// "completed"). A non-zero version is checked as in UpdateTask.
+func (c *Client) SetStatus(ctx context.Context, id int, status string, version int) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodPut, taskPath(id)+"/status", ifMatch(version), api.StatusInput{Status: status}, &doc)
+	return doc, err
+}
+
+// DeleteTask removes a task. Tasks with sub-tasks are only deleted when
+// recursive is set. A non-zero version is checked as in UpdateTask.
+func (c *Client) DeleteTask(ctx context.Context, id int, recursive bool, version int) error {
+	path := taskPath(id)
+	if recursive {
+		path += "?recursive=1"
+	}
+	return c.do(ctx, http.MethodDelete, path, ifMatch(version), nil, nil)
+}
+
+// AddTaskTag assigns a tag to a task, creating the tag if needed.
+func (c *Client) AddTaskTag(ctx context.Context, id int, tag api.TagInput) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodPost, taskPath(id)+"/tags", "", tag, &doc)
+	return doc, err
+}
+
+// RemoveTaskTag unassigns a tag from a task.
+func (c *Client) RemoveTaskTag(ctx context.Context, id int, name string) (api.TaskDocument, error) {
+	var doc api.TaskDocument
+	err := c.do(ctx, http.MethodDelete, taskPath(id)+"/tags/"+escapeTag(name), "", nil, &doc)
+	return doc, err
+}
+
+// ListTags returns every tag with its usage count.
+func (c *Client) ListTags(ctx context.Context) (api.TagList, error) {
+	var list api.TagList
+	err := c.do(ctx, http.MethodGet, "/tags", "", nil, &list)
+	return list, err
+}
+
+// CreateTag adds a tag, creating missing parent tags.
+func (c *Client) CreateTag(ctx context.Context, in api.TagInput) (api.TagDocument, error) {
+	var doc api.TagDocument
+	err := c.do(ctx, http.MethodPost, "/tags", "", in, &doc)
+	return doc, err
+}
+
+// UpdateTag renames or recolors a tag; empty fields are left unchanged.
+func (c *Client) UpdateTag(ctx context.Context, id int, in api.TagInput) (api.TagDocument, error) {
+	var doc api.TagDocument
+	err := c.do(ctx, http.MethodPatch, "/tags/"+strconv.Itoa(id), "", in, &doc)
+	return doc, err
+}
+
+// DeleteTag removes a tag from every task and deletes it.
+func (c *Client) DeleteTag(ctx context.Context, id int) error {
+	return c.do(ctx, http.MethodDelete, "/tags/"+strconv.Itoa(id), "", nil, nil)
+}
+
+func taskPath(id int) string {
+	return "/tasks/" + strconv.Itoa(id)
+}
+
+// escapeTag escapes each segment of a hierarchical tag name.
+func escapeTag(name string) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		parts[i] = url.PathEscape(p)
+	}
+	return strings.Join(parts, "/")
+}
+
+func ifMatch(version int) string {
+	if version == 0 {
+		return ""
+	}
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+func (c *Client) do(ctx context.Context, method, path, match string, body, out any) error {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		r = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.BaseURL+"/api/v1"+path, r)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if match != "" {
+		req.Header.Set("If-Match", match)
+	}
+
+	hc := c.HTTPClient
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+	resp, err := hc.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		var e api.Error
+		if err := json.NewDecoder(resp.Body).Decode(&e); err != nil || e.Error == "" {
+			e.Error = http.StatusText(resp.StatusCode)
+		}
+		return &Error{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
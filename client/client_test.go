@@ -0,0 +1,91 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/client"
+	"github.com/nissyi-gh/flow/internal/server"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// newTestClient starts a server for a store in a temporary directory and
+// returns a client for it.
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+	s, err := store.NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	ts := httptest.NewUnstartedServer(nil)
+	ts.Config.Handler = server.New(s, ts.Listener.Addr().String())
+	ts.Start()
+	t.Cleanup(ts.Close)
+	return client.New(ts.URL)
+}
+
+func TestConflict(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+	doc, err := c.CreateTask(ctx, api.TaskInput{Title: "a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	stale := doc.Task.Version
+
+	patch := api.TaskPatch{Version: stale, Title: api.Set("b")}
+	if _, err := c.UpdateTask(ctx, doc.Task.ID, patch); err != nil {
+		t.Fatal(err)
+	}
+	patch.Title = api.Set("c")
+	if _, err := c.UpdateTask(ctx, doc.Task.ID, patch); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("UpdateTask with stale version = %v, want ErrConflict", err)
+	}
+	if _, err := c.SetStatus(ctx, doc.Task.ID, "completed", stale); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("SetStatus with stale version = %v, want ErrConflict", err)
+	}
+	if err := c.DeleteTask(ctx, doc.Task.ID, false, stale); !errors.Is(err, client.ErrConflict) {
+		t.Errorf("DeleteTask with stale version = %v, want ErrConflict", err)
+	}
+
+	got, err := c.GetTask(ctx, doc.Task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Task.Title != "b" {
+		t.Errorf("title = %q, want %q", got.Task.Title, "b")
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	c := newTestClient(t)
+	ctx := context.Background()
+	if _, err := c.GetTask(ctx, 9); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("GetTask = %v, want ErrNotFound", err)
+	}
+	if err := c.DeleteTask(ctx, 9, false, 0); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteTask = %v, want ErrNotFound", err)
+	}
+	if _, err := c.UpdateTag(ctx, 9, api.TagInput{Color: "39"}); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("UpdateTag = %v, want ErrNotFound", err)
+	}
+	if err := c.DeleteTag(ctx, 9); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteTag = %v, want ErrNotFound", err)
+	}
+
+	tag, err := c.CreateTag(ctx, api.TagInput{Name: "work"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTag(ctx, tag.Tag.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DeleteTag(ctx, tag.Tag.ID); !errors.Is(err, client.ErrNotFound) {
+		t.Errorf("DeleteTag twice = %v, want ErrNotFound", err)
+	}
+}
@@ -0,0 +1,327 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/importer"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+const (
+	formatTodoTxt = "todotxt"
+	formatICS     = "ics"
+	formatCSV     = "csv"
+	formatOrg     = "org"
+	formatMD      = "md"
+)
+
+// importFormats maps --format values of import to importer formats; json
+// is handled separately as a full backup.
+var importFormats = map[string]importer.Format{
+	formatYAML:    importer.FormatYAML,
+	"markdown":    importer.FormatMarkdown,
+	"md":          importer.FormatMarkdown,
+	"todotxt":     importer.FormatTodoTxt,
+	"ics":         importer.FormatICal,
+	"csv":         importer.FormatCSV,
+	"taskwarrior": importer.FormatTaskwarrior,
+}
+
+func runExport(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("export")
+	output := fs.String("o", "", "write to FILE instead of stdout")
+	format := fs.String("format", formatJSON, "json, yaml, todotxt, ics, csv, org or md")
+	query := fs.String("query", "", "only export matching tasks (not for json)")
+	root := fs.Int("root", 0, "only export this task and its sub-tasks (not for json)")
+	columnSpec := fs.String("columns", strings.Join(importer.DefaultCSVColumns, ","), "csv columns")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+	switch *format {
+	case formatJSON:
+		if *query != "" || *root != 0 {
+			return usageErrorf("--query and --root are not supported for json backups")
+		}
+	case formatYAML, formatTodoTxt, formatICS, formatCSV, formatOrg, formatMD:
+	default:
+		return usageErrorf("unknown format %q", *format)
+	}
+	columns, err := importer.ParseCSVColumns(*columnSpec)
+	if err != nil {
+		return usageErrorf("--columns: %v", err)
+	}
+
+	w := out
+	var f *os.File
+	if *output != "" {
+		if f, err = os.Create(*output); err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	var count int
+	if *format != formatJSON {
+		tasks, err := exportTasks(s, *query, *root)
+		if err != nil {
+			return err
+		}
+		title := "flow"
+		if *root != 0 {
+			title = tasks[0].Title
+		}
+		switch *format {
+		case formatCSV:
+			all, listErr := s.List()
+			if listErr != nil {
+				return listErr
+			}
+			err = importer.WriteCSV(w, tasks, all, columns)
+		case formatICS:
+			err = importer.WriteICal(w, tasks)
+		case formatYAML:
+			deps, depsErr := s.AllDependencies()
+			if depsErr != nil {
+				return depsErr
+			}
+			err = importer.WriteYAML(w, tasks, deps)
+		case formatOrg:
+			err = importer.WriteOrg(w, tasks, title)
+		case formatMD:
+			err = importer.WriteMarkdownReport(w, tasks, title)
+		default:
+			err = importer.WriteTodoTxt(w, tasks)
+		}
+		if err != nil {
+			return err
+		}
+		count = len(tasks)
+	} else {
+		doc, err := importer.Backup(s)
+		if err != nil {
+			return err
+		}
+		if err := encode(w, formatJSON, doc); err != nil {
+			return err
+		}
+		count = len(doc.Tasks)
+	}
+
+	if f == nil {
+		return nil
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "exported %d tasks to %s\n", count, *output)
+	return nil
+}
+
+// exportTasks returns the tasks matching query, limited to the subtree of
+// root when it is non-zero. The root task comes first.
+func exportTasks(s *store.TaskStore, query string, root int) ([]model.Task, error) {
+	tasks, err := s.Search(query)
+	if err != nil || root == 0 {
+		return tasks, err
+	}
+	rootTask, err := getTask(s, root)
+	if err != nil {
+		return nil, err
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	in := make(map[int]bool)
+	for _, t := range importer.Subtree(all, root) {
+		in[t.ID] = true
+	}
+	scoped := []model.Task{rootTask}
+	for _, t := range tasks {
+		if in[t.ID] && t.ID != root {
+			scoped = append(scoped, t)
+		}
+	}
+	return scoped, nil
+}
+
+func runImport(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("import")
+	format := fs.String("format", "auto", "auto, json, yaml, markdown, todotxt, ics, csv or taskwarrior")
+	parent := fs.Int("parent", 0, "import under this task (not for json)")
+	dryRun := fs.Bool("dry-run", false, "show what would be created without writing (not for json)")
+	merge := fs.Bool("merge", false, "update matching existing tasks instead of adding duplicates (not for json)")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 1 {
+		return usageErrorf("expected at most one file")
+	}
+
+	var in io.Reader = os.Stdin
+	if len(rest) == 1 && rest[0] != "-" {
+		f, err := os.Open(rest[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		in = f
+	}
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+	text := string(data)
+
+	if *format == "auto" {
+		detected := importer.Detect(text)
+		*format = detectedFormat(detected)
+		if detected != importer.FormatTaskwarrior && strings.HasPrefix(strings.TrimSpace(text), "{") {
+			*format = formatJSON
+		}
+	}
+
+	if *format == formatJSON {
+		if *parent != 0 || *dryRun || *merge {
+			return usageErrorf("--parent, --dry-run and --merge are not supported for json backups")
+		}
+		var doc api.Export
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return fmt.Errorf("read export: %w", err)
+		}
+		res, err := importer.Restore(s, doc)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "imported %d tasks, %d new tags, %d views\n", res.Tasks, res.TagsCreated, res.Views)
+		return nil
+	}
+
+	f, ok := importFormats[*format]
+	if !ok {
+		return usageErrorf("unknown format %q", *format)
+	}
+	var parentID *int
+	if *parent != 0 {
+		if _, err := getTask(s, *parent); err != nil {
+			return err
+		}
+		parentID = parent
+	}
+	nodes, err := importer.Parse(text, f)
+	if err != nil {
+		return err
+	}
+	if *merge {
+		return mergeImport(s, out, nodes, parentID, f, *dryRun)
+	}
+	if *dryRun {
+		return printDryRun(s, out, nodes, f)
+	}
+	count, err := importer.Create(s, nodes, parentID)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "imported %d tasks from %s\n", count, f)
+	return nil
+}
+
+// errDryRun rolls back a merge run with --dry-run.
+var errDryRun = errors.New("dry run")
+
+// mergeImport merges nodes into the tasks under parentID and reports the
+// counts. With dryRun the changes are rolled back.
+func mergeImport(s *store.TaskStore, out io.Writer, nodes []importer.Node, parentID *int, f importer.Format, dryRun bool) error {
+	var res importer.MergeResult
+	err := s.WithTx(func(tx *store.TaskStore) error {
+		var err error
+		if res, err = importer.Merge(tx, nodes, parentID); err != nil {
+			return err
+		}
+		if dryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return err
+	}
+	verb := "merged"
+	if dryRun {
+		verb = "would merge"
+	}
+	fmt.Fprintf(out, "%s %s: %d created, %d updated, %d unchanged\n", verb, f, res.Created, res.Updated, res.Unchanged)
+	return nil
+}
+
+// detectedFormat returns the --format name of an importer format.
+func detectedFormat(f importer.Format) string {
+	for name, v := range importFormats {
+		if v == f && name != "md" {
+			return name
+		}
+	}
+	return formatYAML
+}
+
+// printDryRun lists the tasks an import would create, the tags it would
+// add and any problems that would make it fail.
+func printDryRun(s *store.TaskStore, out io.Writer, nodes []importer.Node, f importer.Format) error {
+	plan, err := importer.Plan(s, nodes)
+	if err != nil {
+		return err
+	}
+	for i, it := range plan.Items {
+		var prefix string
+		for a := it.Parent; a >= 0; a = plan.Items[a].Parent {
+			if plan.IsLast(a) {
+				prefix = "   " + prefix
+			} else {
+				prefix = "│  " + prefix
+			}
+		}
+		branch := "├─ "
+		if plan.IsLast(i) {
+			branch = "└─ "
+		}
+		n := it.Node
+		t := model.Task{Title: n.Title, Status: n.Status, Priority: n.Priority}
+		if n.DueDate != "" {
+			t.DueDate = &n.DueDate
+		}
+		if n.ScheduledOn != "" {
+			t.ScheduledOn = &n.ScheduledOn
+		}
+		for _, name := range n.Tags {
+			t.Tags = append(t.Tags, model.Tag{Name: name})
+		}
+		line := formatLine(t)
+		for _, p := range it.Problems {
+			line += "  ! " + p
+		}
+		fmt.Fprintf(out, "%s%s%s\n", prefix, branch, line)
+	}
+
+	fmt.Fprintf(out, "\nwould import %d tasks from %s", plan.Count(), f)
+	if tags := plan.NewTags(); len(tags) > 0 {
+		fmt.Fprintf(out, " and create tags: %s", strings.Join(tags, ", "))
+	}
+	fmt.Fprintln(out)
+	if problems := plan.Problems(); len(problems) > 0 {
+		return fmt.Errorf("import would fail: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+// Package cli implements the non-interactive flow subcommands used from
+// shell scripts, git hooks and editor integrations.
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+const usage = `Usage: flow [command] [arguments]
+
+Without a command, flow starts the interactive TUI.
+
+Commands:
+  add <title>      Add a task
+                     --parent ID  --due DATE  --scheduled DATE  --today
+                     --tag NAME (repeatable)  --desc TEXT  --priority A-Z
+  list             List tasks as a tree
+                     --query Q  --tag NAME  --tree
+                     --format text|json|tsv|yaml  --json
+  show <id>        Show a task in detail
+                     --format text|json|tsv|yaml  --json
+  done <id>...     Mark tasks as completed
+  start <id>...    Mark tasks as in progress
+  reset <id>...    Mark tasks as not started
+  rm <id>...       Delete tasks (-r is required for tasks with sub-tasks)
+  tag list         List tags with usage counts
+  tag add <id> <name>...     Assign tags, creating them if needed
+  tag rm <id> <name>...      Unassign tags
+  tag rename <old> <new>     Rename a tag
+  tag delete <name>          Delete a tag
+  export           Write every task, tag and view as versioned JSON
+                     -o FILE  --format json|yaml|todotxt|ics|csv|org|md
+                     --query Q  --root ID
+                     --columns id,parent_path,title,... (csv)
+  import [FILE]    Import tasks from FILE (default stdin); a json backup
+                     restores everything with new task IDs
+                     --format auto|json|yaml|markdown|todotxt|ics|csv|
+                              taskwarrior
+                     --parent ID  --dry-run  --merge
+  serve            Serve the HTTP/JSON API (see the client package)
+                     --addr HOST:PORT  (default 127.0.0.1:7070)
+  help             Show this help
+
+JSON and YAML output follow a versioned schema; see the api package.
+
+DATE accepts YYYY-MM-DD, today, tomorrow or an offset such as +3d or +2w.
+`
+
+// errUsage marks errors caused by invalid command-line arguments.
+var errUsage = errors.New("usage error")
+
+type command func(s *store.TaskStore, args []string, out io.Writer) error
+
+var commands = map[string]command{
+	"add":    runAdd,
+	"list":   runList,
+	"ls":     runList,
+	"show":   runShow,
+	"done":   runDone,
+	"start":  runStart,
+	"reset":  runReset,
+	"rm":     runRemove,
+	"tag":    runTag,
+	"serve":  runServe,
+	"export": runExport,
+	"import": runImport,
+}
+
+// Run executes the subcommand in args and returns the process exit code:
+// 0 on success, 1 on failure and 2 on invalid usage.
+func Run(s *store.TaskStore, args []string, stdout, stderr io.Writer) int {
+	name := args[0]
+	if name == "help" || name == "-h" || name == "--help" {
+		fmt.Fprint(stdout, usage)
+		return 0
+	}
+
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(stderr, "flow: unknown command %q\n\n%s", name, usage)
+		return 2
+	}
+	if err := cmd(s, args[1:], stdout); err != nil {
+		fmt.Fprintf(stderr, "flow %s: %v\n", name, err)
+		if errors.Is(err, errUsage) {
+			return 2
+		}
+		return 1
+	}
+	return 0
+}
+
+// usageErrorf reports invalid arguments.
+func usageErrorf(format string, args ...any) error {
+	return fmt.Errorf("%w: %s", errUsage, fmt.Sprintf(format, args...))
+}
+
+// newFlagSet returns a flag set that reports errors instead of exiting.
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseFlags parses fs from args, allowing flags and positional arguments
+// to be interleaved, and returns the positional arguments.
+func parseFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, usageErrorf("%v", err)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string     { return strings.Join(*l, ",") }
+func (l *stringList) Set(v string) error { *l = append(*l, v); return nil }
+
+func parseID(arg string) (int, error) {
+	id, err := strconv.Atoi(arg)
+	if err != nil || id <= 0 {
+		return 0, usageErrorf("invalid task id %q", arg)
+	}
+	return id, nil
+}
+
+func parseIDs(args []string) ([]int, error) {
+	if len(args) == 0 {
+		return nil, usageErrorf("at least one task id is required")
+	}
+	ids := make([]int, len(args))
+	for i, arg := range args {
+		id, err := parseID(arg)
+		if err != nil {
+			return nil, err
+		}
+		ids[i] = id
+	}
+	return ids, nil
+}
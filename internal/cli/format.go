@@ -0,0 +1,123 @@
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/model"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatText = "text"
+	formatJSON = "json"
+	formatTSV  = "tsv"
+	formatYAML = "yaml"
+)
+
+// tsvHeader names the columns of --format=tsv output.
+var tsvHeader = []string{"id", "parent_id", "status", "due_date", "scheduled_on", "tags", "title"}
+
+// formatFlags registers --format and its --json shorthand on fs.
+type formatFlags struct {
+	format *string
+	json   *bool
+}
+
+func addFormatFlags(fs *flag.FlagSet) formatFlags {
+	return formatFlags{
+		format: fs.String("format", formatText, "output format: text, json, tsv or yaml"),
+		json:   fs.Bool("json", false, "shorthand for --format=json"),
+	}
+}
+
+// value returns the selected format after validating it.
+func (f formatFlags) value() (string, error) {
+	if *f.json {
+		return formatJSON, nil
+	}
+	switch *f.format {
+	case formatText, formatJSON, formatTSV, formatYAML:
+		return *f.format, nil
+	}
+	return "", usageErrorf("unknown format %q", *f.format)
+}
+
+// writeTaskList emits tasks as a flat list or, with tree set, nested under
+// their parents. all holds every task in the store and is used to compute
+// ancestor IDs.
+func writeTaskList(out io.Writer, format string, tasks, all []model.Task, tree bool) error {
+	switch format {
+	case formatTSV:
+		if tree {
+			return usageErrorf("--tree is not supported with --format=tsv")
+		}
+		fmt.Fprintln(out, strings.Join(tsvHeader, "\t"))
+		for _, t := range tasks {
+			fmt.Fprintln(out, strings.Join(tsvRow(t), "\t"))
+		}
+		return nil
+	case formatJSON, formatYAML:
+		return encode(out, format, api.NewTaskList(tasks, all, tree))
+	}
+
+	width := 1
+	for _, t := range tasks {
+		width = max(width, len(fmt.Sprint(t.ID)))
+	}
+	walkForest(buildForest(tasks), func(n *node, prefix string) {
+		fmt.Fprintf(out, "%*d %s%s\n", width, n.Task.ID, prefix, formatLine(n.Task))
+	})
+	return nil
+}
+
+func tsvRow(t model.Task) []string {
+	var tags []string
+	for _, tag := range t.Tags {
+		tags = append(tags, tag.Name)
+	}
+	parent := ""
+	if t.ParentID != nil {
+		parent = fmt.Sprint(*t.ParentID)
+	}
+	return []string{
+		fmt.Sprint(t.ID),
+		parent,
+		t.Status.String(),
+		optionalEmpty(t.DueDate),
+		optionalEmpty(t.ScheduledOn),
+		strings.Join(tags, ","),
+		tsvEscape(t.Title),
+	}
+}
+
+// tsvEscape keeps a value on a single TSV field.
+func tsvEscape(s string) string {
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
+
+func optionalEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// encode writes v as indented JSON or YAML.
+func encode(out io.Writer, format string, v any) error {
+	if format == formatYAML {
+		enc := yaml.NewEncoder(out)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return err
+		}
+		return enc.Close()
+	}
+	enc := json.NewEncoder(out)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
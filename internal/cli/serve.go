@@ -0,0 +1,25 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/nissyi-gh/flow/internal/server"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+func runServe(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("serve")
+	addr := fs.String("addr", "127.0.0.1:7070", "listen address")
+	rest, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return usageErrorf("unexpected argument %q", rest[0])
+	}
+
+	fmt.Fprintf(out, "flow API listening on http://%s%s\n", *addr, server.Prefix)
+	return http.ListenAndServe(*addr, server.New(s, *addr))
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+func runTag(s *store.TaskStore, args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return usageErrorf("tag requires a subcommand: list, add, rm, rename or delete")
+	}
+	sub, args := args[0], args[1:]
+	switch sub {
+	case "list", "ls":
+		return runTagList(s, args, out)
+	case "add":
+		return runTagAssign(s, args, true)
+	case "rm":
+		return runTagAssign(s, args, false)
+	case "rename":
+		if len(args) != 2 {
+			return usageErrorf("tag rename requires <old> <new>")
+		}
+		tag, err := getTag(s, args[0])
+		if err != nil {
+			return err
+		}
+		return s.RenameTag(tag.ID, args[1])
+	case "delete":
+		if len(args) != 1 {
+			return usageErrorf("tag delete requires <name>")
+		}
+		tag, err := getTag(s, args[0])
+		if err != nil {
+			return err
+		}
+		return s.DeleteTag(tag.ID)
+	}
+	return usageErrorf("unknown tag subcommand %q", sub)
+}
+
+func runTagList(s *store.TaskStore, args []string, out io.Writer) error {
+	if len(args) > 0 {
+		return usageErrorf("unexpected argument %q", args[0])
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		return err
+	}
+	usage, err := s.TagUsage()
+	if err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		fmt.Fprintf(out, "%s\t%d\n", tag.Name, usage[tag.ID])
+	}
+	return nil
+}
+
+// runTagAssign adds or removes the named tags on a task.
+func runTagAssign(s *store.TaskStore, args []string, assign bool) error {
+	if len(args) < 2 {
+		return usageErrorf("requires <id> <name>...")
+	}
+	id, err := parseID(args[0])
+	if err != nil {
+		return err
+	}
+	return s.WithTx(func(tx *store.TaskStore) error {
+		if _, err := getTask(tx, id); err != nil {
+			return err
+		}
+		if assign {
+			return assignTags(tx, id, args[1:])
+		}
+		for _, name := range args[1:] {
+			tag, err := getTag(tx, name)
+			if err != nil {
+				return err
+			}
+			if err := tx.UnassignTag(id, tag.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getTag looks a tag up by name, turning a missing row into a readable error.
+func getTag(s *store.TaskStore, name string) (model.Tag, error) {
+	tag, err := s.TagByName(name)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Tag{}, fmt.Errorf("tag %q not found", name)
+	}
+	return tag, err
+}
@@ -0,0 +1,374 @@
+package cli
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+func runAdd(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("add")
+	parent := fs.Int("parent", 0, "parent task id")
+	due := fs.String("due", "", "due date")
+	scheduled := fs.String("scheduled", "", "scheduled date")
+	today := fs.Bool("today", false, "schedule for today")
+	desc := fs.String("desc", "", "description")
+	prio := fs.String("priority", "", "priority A-Z")
+	var tags stringList
+	fs.Var(&tags, "tag", "tag name")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+
+	title := strings.TrimSpace(strings.Join(pos, " "))
+	if title == "" {
+		return usageErrorf("a title is required")
+	}
+	if *today {
+		*scheduled = "today"
+	}
+	var dueDate, scheduledOn *string
+	if *due != "" {
+		d, err := store.ResolveDate(*due)
+		if err != nil {
+			return usageErrorf("--due: %v", err)
+		}
+		dueDate = &d
+	}
+	if *scheduled != "" {
+		d, err := store.ResolveDate(*scheduled)
+		if err != nil {
+			return usageErrorf("--scheduled: %v", err)
+		}
+		scheduledOn = &d
+	}
+	priority, err := model.ParsePriority(strings.ToUpper(*prio))
+	if err != nil {
+		return usageErrorf("--priority: %v", err)
+	}
+	var parentID *int
+	if *parent != 0 {
+		if _, err := getTask(s, *parent); err != nil {
+			return err
+		}
+		parentID = parent
+	}
+
+	var task model.Task
+	err = s.WithTx(func(tx *store.TaskStore) error {
+		t, err := tx.Add(title, parentID)
+		if err != nil {
+			return err
+		}
+		if *desc != "" {
+			if err := tx.UpdateDescription(t.ID, desc); err != nil {
+				return err
+			}
+		}
+		if priority != 0 {
+			if err := tx.SetPriority(t.ID, priority); err != nil {
+				return err
+			}
+		}
+		if dueDate != nil {
+			if err := tx.SetDueDate(t.ID, dueDate); err != nil {
+				return err
+			}
+		}
+		if scheduledOn != nil {
+			if err := tx.SetScheduledOn(t.ID, scheduledOn); err != nil {
+				return err
+			}
+		}
+		if err := assignTags(tx, t.ID, tags); err != nil {
+			return err
+		}
+		task, err = tx.GetByID(t.ID)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(out, task.ID)
+	return nil
+}
+
+func runList(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("list")
+	query := fs.String("query", "", "filter query")
+	tree := fs.Bool("tree", false, "nest sub-tasks under their parents")
+	var tags stringList
+	fs.Var(&tags, "tag", "tag name")
+	formatFlag := addFormatFlags(fs)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) > 0 {
+		return usageErrorf("unexpected argument %q", pos[0])
+	}
+	format, err := formatFlag.value()
+	if err != nil {
+		return err
+	}
+
+	q := *query
+	for _, t := range tags {
+		term, err := quoteTerm(t)
+		if err != nil {
+			return err
+		}
+		q += " tag:" + term
+	}
+	tasks, err := s.Search(strings.TrimSpace(q))
+	if err != nil {
+		return err
+	}
+	all, err := s.List()
+	if err != nil {
+		return err
+	}
+	return writeTaskList(out, format, tasks, all, *tree)
+}
+
+func runShow(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("show")
+	formatFlag := addFormatFlags(fs)
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	if len(pos) != 1 {
+		return usageErrorf("exactly one task id is required")
+	}
+	format, err := formatFlag.value()
+	if err != nil {
+		return err
+	}
+	id, err := parseID(pos[0])
+	if err != nil {
+		return err
+	}
+	t, err := getTask(s, id)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case formatTSV:
+		fmt.Fprintln(out, strings.Join(tsvHeader, "\t"))
+		fmt.Fprintln(out, strings.Join(tsvRow(t), "\t"))
+		return nil
+	case formatJSON, formatYAML:
+		all, err := s.List()
+		if err != nil {
+			return err
+		}
+		doc := api.TaskDocument{
+			SchemaVersion: api.SchemaVersion,
+			Task:          api.NewTaskList([]model.Task{t}, all, false).Tasks[0],
+		}
+		return encode(out, format, doc)
+	}
+
+	fmt.Fprintf(out, "#%d %s\n", t.ID, t.Title)
+	fmt.Fprintf(out, "status:       %s\n", t.Status)
+	priority := t.PriorityLetter()
+	if priority == "" {
+		priority = "-"
+	}
+	fmt.Fprintf(out, "priority:     %s\n", priority)
+	fmt.Fprintf(out, "parent:       %s\n", optionalInt(t.ParentID))
+	fmt.Fprintf(out, "due_date:     %s\n", optional(t.DueDate))
+	fmt.Fprintf(out, "scheduled_on: %s\n", optional(t.ScheduledOn))
+	var tagNames []string
+	for _, tag := range t.Tags {
+		tagNames = append(tagNames, tag.Name)
+	}
+	tagList := strings.Join(tagNames, ", ")
+	if tagList == "" {
+		tagList = "-"
+	}
+	fmt.Fprintf(out, "tags:         %s\n", tagList)
+	deps, err := s.Dependencies(t.ID)
+	if err != nil {
+		return err
+	}
+	depList := "-"
+	if len(deps) > 0 {
+		refs := make([]string, len(deps))
+		for i, id := range deps {
+			refs[i] = fmt.Sprintf("#%d", id)
+		}
+		depList = strings.Join(refs, ", ")
+	}
+	fmt.Fprintf(out, "depends_on:   %s\n", depList)
+	fmt.Fprintf(out, "created_at:   %s\n", t.CreatedAt.Format("2006-01-02 15:04:05"))
+	fmt.Fprintf(out, "updated_at:   %s\n", t.UpdatedAt.Format("2006-01-02 15:04:05"))
+	if t.Description != nil && *t.Description != "" {
+		fmt.Fprintf(out, "\n%s\n", *t.Description)
+	}
+	return nil
+}
+
+func runDone(s *store.TaskStore, args []string, out io.Writer) error {
+	return setStatus(s, args, model.StatusCompleted)
+}
+
+func runStart(s *store.TaskStore, args []string, out io.Writer) error {
+	return setStatus(s, args, model.StatusInProgress)
+}
+
+func runReset(s *store.TaskStore, args []string, out io.Writer) error {
+	return setStatus(s, args, model.StatusNotStarted)
+}
+
+func setStatus(s *store.TaskStore, args []string, status model.TaskStatus) error {
+	ids, err := parseIDs(args)
+	if err != nil {
+		return err
+	}
+	return s.WithTx(func(tx *store.TaskStore) error {
+		for _, id := range ids {
+			if _, err := getTask(tx, id); err != nil {
+				return err
+			}
+			if err := tx.UpdateStatus(id, status); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func runRemove(s *store.TaskStore, args []string, out io.Writer) error {
+	fs := newFlagSet("rm")
+	recursive := fs.Bool("r", false, "also delete sub-tasks")
+	pos, err := parseFlags(fs, args)
+	if err != nil {
+		return err
+	}
+	ids, err := parseIDs(pos)
+	if err != nil {
+		return err
+	}
+	return s.WithTx(func(tx *store.TaskStore) error {
+		for _, id := range ids {
+			if _, err := getTask(tx, id); err != nil {
+				return err
+			}
+			hasChildren, err := tx.HasChildren(id)
+			if err != nil {
+				return err
+			}
+			if hasChildren && !*recursive {
+				return fmt.Errorf("task %d has sub-tasks; use -r to delete them too", id)
+			}
+			if err := tx.Delete(id); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// getTask loads a task, turning a missing row into a readable error.
+func getTask(s *store.TaskStore, id int) (model.Task, error) {
+	t, err := s.GetByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return model.Task{}, fmt.Errorf("task %d not found", id)
+	}
+	return t, err
+}
+
+// assignTags attaches tags by name, creating missing ones. Each new tag
+// gets the next palette color.
+func assignTags(s *store.TaskStore, taskID int, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	existing, err := s.ListTags()
+	if err != nil {
+		return err
+	}
+	known := make(map[string]bool, len(existing))
+	for _, t := range existing {
+		known[t.Name] = true
+	}
+	for _, name := range names {
+		tag, err := s.EnsureTag(name, model.PaletteColor(len(known)))
+		if err != nil {
+			return err
+		}
+		known[tag.Name] = true
+		if err := s.AssignTag(taskID, tag.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatLine renders a task on one line: checkbox, title, tags and dates.
+func formatLine(t model.Task) string {
+	var sb strings.Builder
+	sb.WriteString(statusCheck(t.Status))
+	sb.WriteString(" ")
+	if p := t.PriorityLetter(); p != "" {
+		sb.WriteString("(" + p + ") ")
+	}
+	sb.WriteString(t.Title)
+	for _, tag := range t.Tags {
+		sb.WriteString(" #" + tag.Name)
+	}
+	if t.DueDate != nil {
+		sb.WriteString(" due:" + *t.DueDate)
+	}
+	if t.ScheduledOn != nil {
+		sb.WriteString(" scheduled:" + *t.ScheduledOn)
+	}
+	return sb.String()
+}
+
+func statusCheck(s model.TaskStatus) string {
+	switch s {
+	case model.StatusInProgress:
+		return "[-]"
+	case model.StatusCompleted:
+		return "[x]"
+	default:
+		return "[ ]"
+	}
+}
+
+func optional(s *string) string {
+	if s == nil {
+		return "-"
+	}
+	return *s
+}
+
+func optionalInt(i *int) string {
+	if i == nil {
+		return "-"
+	}
+	return fmt.Sprint(*i)
+}
+
+// quoteTerm quotes a query value containing whitespace. Queries have no
+// way to escape a double quote, so values containing one are rejected.
+func quoteTerm(v string) (string, error) {
+	if strings.Contains(v, `"`) {
+		return "", usageErrorf("%q: double quotes are not allowed in a query value", v)
+	}
+	if strings.ContainsAny(v, " \t") {
+		return `"` + v + `"`, nil
+	}
+	return v, nil
+}
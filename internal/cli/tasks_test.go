@@ -0,0 +1,58 @@
+package cli
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// run runs a flow command against s and returns its exit code and output.
+func run(s *store.TaskStore, args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := Run(s, args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+func newTestStore(t *testing.T) *store.TaskStore {
+	t.Helper()
+	s, err := store.NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestAddColorsEachNewTag(t *testing.T) {
+	s := newTestStore(t)
+	if code, _, stderr := run(s, "add", "Write report", "--tag", "a", "--tag", "b", "--tag", "c"); code != 0 {
+		t.Fatalf("add failed: %s", stderr)
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 3 {
+		t.Fatalf("got %d tags, want 3", len(tags))
+	}
+	for i, tag := range tags {
+		if want := model.PaletteColor(i); tag.Color != want {
+			t.Errorf("tag %s has color %s, want %s", tag.Name, tag.Color, want)
+		}
+	}
+}
+
+func TestListRejectsQuotesInTags(t *testing.T) {
+	s := newTestStore(t)
+	code, _, stderr := run(s, "list", "--tag", `a" OR "b`)
+	if code == 0 {
+		t.Fatal("list with a quoted tag succeeded")
+	}
+	if !strings.Contains(stderr, "double quotes") {
+		t.Errorf("stderr = %q, want it to mention double quotes", stderr)
+	}
+}
@@ -0,0 +1,49 @@
+package cli
+
+import "github.com/nissyi-gh/flow/internal/model"
+
+// node is a task together with its sub-tasks.
+type node struct {
+	Task     model.Task
+	Children []*node
+}
+
+// buildForest nests tasks under their parents, keeping the input order among
+// siblings. Tasks whose parent is not in the list become roots.
+func buildForest(tasks []model.Task) []*node {
+	nodes := make(map[int]*node, len(tasks))
+	for _, t := range tasks {
+		nodes[t.ID] = &node{Task: t}
+	}
+	var roots []*node
+	for _, t := range tasks {
+		n := nodes[t.ID]
+		if t.ParentID != nil {
+			if parent, ok := nodes[*t.ParentID]; ok {
+				parent.Children = append(parent.Children, n)
+				continue
+			}
+		}
+		roots = append(roots, n)
+	}
+	return roots
+}
+
+// walkForest visits every node depth-first. prefix holds the tree-drawing
+// characters for the node, matching the TUI.
+func walkForest(roots []*node, visit func(n *node, prefix string)) {
+	var walk func(n *node, indent string, prefix string)
+	walk = func(n *node, indent string, prefix string) {
+		visit(n, prefix)
+		for i, child := range n.Children {
+			if i == len(n.Children)-1 {
+				walk(child, indent+"    ", indent+" └─ ")
+			} else {
+				walk(child, indent+" │  ", indent+" ├─ ")
+			}
+		}
+	}
+	for _, r := range roots {
+		walk(r, "", "")
+	}
+}
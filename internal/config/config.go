@@ -0,0 +1,123 @@
+// Package config reads the optional flow configuration file,
+// $XDG_CONFIG_HOME/flow/config.yaml (~/.config/flow/config.yaml by
+// default).
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the settings of config.yaml. The zero value is the default
+// configuration.
+type Config struct {
+	// Language selects the language of the TUI and the built-in prompts,
+	// "en" or "ja". If it is empty, the locale decides.
+	Language string `yaml:"language"`
+	LLM      LLM    `yaml:"llm"`
+}
+
+// Lang returns Language, or "ja" if the locale in LC_ALL, LC_MESSAGES or
+// LANG is Japanese and "en" otherwise.
+func (c Config) Lang() string {
+	if c.Language != "" {
+		return c.Language
+	}
+	for _, env := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if v := os.Getenv(env); v != "" {
+			if strings.HasPrefix(v, "ja") {
+				return "ja"
+			}
+			return "en"
+		}
+	}
+	return "en"
+}
+
+// LLM configures an OpenAI-compatible chat completions endpoint.
+//
+//	llm:
+//	  endpoint: http://localhost:11434/v1   # Ollama; or https://api.openai.com/v1
+//	  model: llama3.1
+//	  api_key_env: OPENAI_API_KEY            # or api_key: sk-...
+//	  timeout: 2m
+type LLM struct {
+	// Endpoint is the API base URL; /chat/completions is appended.
+	Endpoint string `yaml:"endpoint"`
+	Model    string `yaml:"model"`
+	// APIKey is sent as a bearer token. If it is empty, the environment
+	// variable named by APIKeyEnv is used, FLOW_LLM_API_KEY by default.
+	APIKey      string        `yaml:"api_key"`
+	APIKeyEnv   string        `yaml:"api_key_env"`
+	Temperature *float64      `yaml:"temperature"`
+	Timeout     time.Duration `yaml:"timeout"` // for the reply to start; 0 for no limit
+}
+
+// Enabled reports whether an endpoint is configured.
+func (l LLM) Enabled() bool {
+	return l.Endpoint != ""
+}
+
+// Key returns the API key, from the file or the environment.
+func (l LLM) Key() string {
+	if l.APIKey != "" {
+		return l.APIKey
+	}
+	env := l.APIKeyEnv
+	if env == "" {
+		env = "FLOW_LLM_API_KEY"
+	}
+	return os.Getenv(env)
+}
+
+// Dir returns the flow configuration directory. It is not created.
+func Dir() (string, error) {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "flow"), nil
+}
+
+// Load reads config.yaml from Dir. A missing file is not an error and
+// yields the default configuration.
+func Load() (Config, error) {
+	dir, err := Dir()
+	if err != nil {
+		return Config{}, fmt.Errorf("determine config dir: %w", err)
+	}
+	cfg, err := LoadFile(filepath.Join(dir, "config.yaml"))
+	if errors.Is(err, fs.ErrNotExist) {
+		return Config{}, nil
+	}
+	return cfg, err
+}
+
+// LoadFile reads the configuration file at path. Unknown keys are
+// reported, so that typos do not go unnoticed.
+func LoadFile(path string) (Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+	var cfg Config
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&cfg); err != nil && err != io.EOF {
+		return Config{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
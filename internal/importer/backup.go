@@ -0,0 +1,275 @@
+package importer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// Backup returns an export document holding every task, tag, saved view
+// and dependency.
+func Backup(s *store.TaskStore) (api.Export, error) {
+	tasks, err := s.List()
+	if err != nil {
+		return api.Export{}, err
+	}
+	deps, err := s.AllDependencies()
+	if err != nil {
+		return api.Export{}, err
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		return api.Export{}, err
+	}
+	views, err := s.ListViews()
+	if err != nil {
+		return api.Export{}, err
+	}
+
+	doc := api.Export{
+		Format:        api.ExportFormat,
+		SchemaVersion: api.SchemaVersion,
+		ExportedAt:    time.Now().UTC().Truncate(time.Second),
+		Tags:          []api.ExportTag{},
+		Views:         []api.View{},
+		Tasks:         []api.Task{},
+	}
+	for _, t := range tags {
+		doc.Tags = append(doc.Tags, api.NewExportTag(t))
+	}
+	for _, v := range views {
+		doc.Views = append(doc.Views, api.View{Name: v.Name, Query: v.Query})
+	}
+	// A tree walk lists every parent before its children, which creation
+	// order does not guarantee once tasks have been moved.
+	doc.Tasks = api.NewTaskList(parentsFirst(tasks), tasks, false).Tasks
+	exported := make(map[int]bool, len(doc.Tasks))
+	for _, t := range doc.Tasks {
+		exported[t.ID] = true
+	}
+	for _, t := range doc.Tasks {
+		for _, dep := range deps[t.ID] {
+			// Restore rejects links to tasks that are not in the document.
+			if !exported[dep] {
+				continue
+			}
+			doc.Dependencies = append(doc.Dependencies, api.Dependency{TaskID: t.ID, DependsOnID: dep})
+		}
+	}
+	return doc, nil
+}
+
+// parentsFirst orders tasks depth-first from the roots, keeping the
+// original order among siblings. Tasks whose parent is not in tasks are
+// treated as roots.
+func parentsFirst(tasks []model.Task) []model.Task {
+	present := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		present[t.ID] = true
+	}
+	children := make(map[int][]model.Task)
+	var roots []model.Task
+	for _, t := range tasks {
+		if t.ParentID == nil || !present[*t.ParentID] {
+			roots = append(roots, t)
+		} else {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+	ordered := make([]model.Task, 0, len(tasks))
+	var walk func(t model.Task)
+	walk = func(t model.Task) {
+		ordered = append(ordered, t)
+		for _, c := range children[t.ID] {
+			walk(c)
+		}
+	}
+	for _, r := range roots {
+		walk(r)
+	}
+	return ordered
+}
+
+// RestoreResult summarizes a Restore.
+type RestoreResult struct {
+	Tasks       int
+	TagsCreated int
+	Views       int
+	// IDs maps task IDs in the document to the IDs assigned on import.
+	IDs map[int]int
+}
+
+// Restore recreates the tasks, tags, views and dependencies of doc in s in
+// a single transaction, so a failed import leaves the database unchanged.
+// Task IDs are remapped; tags and views that already exist by name are reused and
+// keep their current settings.
+func Restore(s *store.TaskStore, doc api.Export) (RestoreResult, error) {
+	if doc.Format != api.ExportFormat {
+		return RestoreResult{}, fmt.Errorf("not a flow export (format %q)", doc.Format)
+	}
+	if doc.SchemaVersion < 1 || doc.SchemaVersion > api.SchemaVersion {
+		return RestoreResult{}, fmt.Errorf("unsupported schema version %d", doc.SchemaVersion)
+	}
+	tasks, err := decodeTasks(doc.Tasks)
+	if err != nil {
+		return RestoreResult{}, err
+	}
+
+	res := RestoreResult{IDs: make(map[int]int, len(tasks))}
+	err = s.WithTx(func(tx *store.TaskStore) error {
+		tagIDs, created, err := restoreTags(tx, doc.Tags)
+		if err != nil {
+			return err
+		}
+		res.TagsCreated = created
+
+		for i, t := range tasks {
+			oldID := t.ID
+			if t.ParentID != nil {
+				pid := res.IDs[*t.ParentID]
+				t.ParentID = &pid
+			}
+			for _, tag := range doc.Tasks[i].Tags {
+				id, ok := tagIDs[tag.Name]
+				if !ok {
+					tg, err := tx.EnsureTag(tag.Name, tag.Color)
+					if err != nil {
+						return fmt.Errorf("task %d: %w", oldID, err)
+					}
+					id, tagIDs[tag.Name] = tg.ID, tg.ID
+					res.TagsCreated++
+				}
+				t.Tags = append(t.Tags, model.Tag{ID: id, Name: tag.Name})
+			}
+			restored, err := tx.Restore(t)
+			if err != nil {
+				return err
+			}
+			res.IDs[oldID] = restored.ID
+			res.Tasks++
+		}
+
+		for _, d := range doc.Dependencies {
+			id, ok1 := res.IDs[d.TaskID]
+			dep, ok2 := res.IDs[d.DependsOnID]
+			if !ok1 || !ok2 {
+				return fmt.Errorf("dependency of task %d on %d: unknown task", d.TaskID, d.DependsOnID)
+			}
+			if err := tx.AddDependency(id, dep); err != nil {
+				return err
+			}
+		}
+
+		existing, err := tx.ListViews()
+		if err != nil {
+			return err
+		}
+		have := make(map[string]bool, len(existing))
+		for _, v := range existing {
+			have[v.Name] = true
+		}
+		for _, v := range doc.Views {
+			if have[v.Name] {
+				continue
+			}
+			if _, err := tx.SaveView(v.Name, v.Query); err != nil {
+				return err
+			}
+			res.Views++
+		}
+		return nil
+	})
+	if err != nil {
+		return RestoreResult{}, err
+	}
+	return res, nil
+}
+
+// decodeTasks validates the tasks of an export document and converts them
+// to model tasks still carrying their document IDs.
+func decodeTasks(in []api.Task) ([]model.Task, error) {
+	seen := make(map[int]bool, len(in))
+	tasks := make([]model.Task, 0, len(in))
+	for _, at := range in {
+		if at.ID <= 0 || seen[at.ID] {
+			return nil, fmt.Errorf("task %q: missing or duplicate id %d", at.Title, at.ID)
+		}
+		if at.Title == "" {
+			return nil, fmt.Errorf("task %d: title is required", at.ID)
+		}
+		if at.ParentID != nil && !seen[*at.ParentID] {
+			return nil, fmt.Errorf("task %d: parent %d must be listed before it", at.ID, *at.ParentID)
+		}
+		status, err := model.ParseTaskStatus(at.Status)
+		if err != nil {
+			return nil, fmt.Errorf("task %d: %w", at.ID, err)
+		}
+		var priority int
+		if at.Priority != nil {
+			if priority, err = model.ParsePriority(*at.Priority); err != nil {
+				return nil, fmt.Errorf("task %d: %w", at.ID, err)
+			}
+		}
+		for _, d := range []*string{at.DueDate, at.ScheduledOn} {
+			if d == nil {
+				continue
+			}
+			if _, err := time.Parse("2006-01-02", *d); err != nil {
+				return nil, fmt.Errorf("task %d: invalid date %q", at.ID, *d)
+			}
+		}
+		seen[at.ID] = true
+		tasks = append(tasks, model.Task{
+			ID:          at.ID,
+			Title:       at.Title,
+			Description: at.Description,
+			Status:      status,
+			Completed:   status == model.StatusCompleted,
+			Priority:    priority,
+			ParentID:    at.ParentID,
+			ScheduledOn: at.ScheduledOn,
+			DueDate:     at.DueDate,
+			CreatedAt:   at.CreatedAt,
+			UpdatedAt:   at.UpdatedAt,
+		})
+	}
+	return tasks, nil
+}
+
+// restoreTags creates the tags of an export that do not exist yet, parents
+// first, and returns the store ID of every tag by name.
+func restoreTags(s *store.TaskStore, tags []api.ExportTag) (map[string]int, int, error) {
+	existing, err := s.ListTags()
+	if err != nil {
+		return nil, 0, err
+	}
+	ids := make(map[string]int, len(existing)+len(tags))
+	for _, t := range existing {
+		ids[t.Name] = t.ID
+	}
+
+	created := 0
+	for _, t := range tags {
+		if _, ok := ids[t.Name]; ok {
+			continue
+		}
+		// EnsureTag only colors top-level tags, and may already have created
+		// this one as the parent of an earlier entry, so set the color
+		// explicitly.
+		tag, err := s.EnsureTag(t.Name, "")
+		if err != nil {
+			return nil, 0, err
+		}
+		if !t.Inherited {
+			if err := s.SetTagColor(tag.ID, t.Color); err != nil {
+				return nil, 0, err
+			}
+		}
+		ids[t.Name] = tag.ID
+		created++
+	}
+	return ids, created, nil
+}
@@ -0,0 +1,56 @@
+package importer
+
+import (
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+func TestBackupSkipsDanglingDependencies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flow.db")
+	s, err := store.NewTaskStore(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	a, err := s.Add("a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Add("b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDependency(a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// A connection without foreign keys, as older versions used, leaves a
+	// link to a deleted task behind.
+	raw, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer raw.Close()
+	if _, err := raw.Exec("INSERT INTO task_dependencies (task_id, depends_on_id) VALUES (?, 999)", a.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	doc, err := Backup(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(doc.Dependencies) != 1 || doc.Dependencies[0].DependsOnID != b.ID {
+		t.Fatalf("Dependencies = %+v, want only %d on %d", doc.Dependencies, a.ID, b.ID)
+	}
+
+	res, err := Restore(newTestStore(t), doc)
+	if err != nil {
+		t.Fatalf("Restore: %v", err)
+	}
+	if res.Tasks != 2 {
+		t.Errorf("restored %d tasks, want 2", res.Tasks)
+	}
+}
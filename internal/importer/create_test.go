@@ -0,0 +1,75 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+func TestCreateRollsBack(t *testing.T) {
+	s := newTestStore(t)
+	// The priority is only checked by the store, so the grandchild fails
+	// after its ancestors and their tag were written.
+	nodes := []Node{{
+		Title: "Trip",
+		Tags:  []string{"travel"},
+		Children: []Node{
+			{Title: "Visa"},
+			{Title: "Flights", Children: []Node{{Title: "Compare fares", Priority: 99}}},
+		},
+	}}
+	n, err := Create(s, nodes, nil)
+	if err == nil {
+		t.Fatal("Create succeeded")
+	}
+	if n != 0 {
+		t.Errorf("Create = %d, want 0 on error", n)
+	}
+	assertEmpty(t, s)
+
+	nodes[0].Children[1].Children[0].Priority = 1
+	if n, err = Create(s, nodes, nil); err != nil {
+		t.Fatal(err)
+	}
+	if n != 4 {
+		t.Errorf("Create = %d, want 4", n)
+	}
+}
+
+func TestValidateBeforeWriting(t *testing.T) {
+	s := newTestStore(t)
+	nodes := []Node{{
+		Title: "Trip",
+		Children: []Node{
+			{Title: "Visa", DueDate: "2026-13-01"},
+			{Title: "  "},
+		},
+	}}
+	_, err := Create(s, nodes, nil)
+	if err == nil {
+		t.Fatal("Create succeeded")
+	}
+	for _, want := range []string{"2 problems", `invalid due date "2026-13-01"`, "task title is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+	assertEmpty(t, s)
+}
+
+// assertEmpty fails unless s has no tasks and no tags.
+func assertEmpty(t *testing.T, s *store.TaskStore) {
+	t.Helper()
+	tasks, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	tags, err := s.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tasks) != 0 || len(tags) != 0 {
+		t.Errorf("store has %d tasks and %d tags, want none", len(tasks), len(tags))
+	}
+}
@@ -0,0 +1,378 @@
+package importer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// CSVPathSeparator joins the ancestor titles in the parent_path column.
+const CSVPathSeparator = " > "
+
+// csvPathEscaper escapes a title in the parent_path column, so that a title
+// containing the separator is not split on import.
+var csvPathEscaper = strings.NewReplacer(`\`, `\\`, `>`, `\>`)
+
+// CSVColumns lists the columns WriteCSV can emit.
+var CSVColumns = []string{
+	"id", "parent_id", "parent_path", "title", "status", "priority",
+	"due", "scheduled", "tags", "description", "created", "updated",
+}
+
+// DefaultCSVColumns is the column set used when none is given.
+var DefaultCSVColumns = []string{"id", "parent_path", "title", "status", "due", "scheduled", "tags"}
+
+// csvAliases maps alternative header names accepted on import.
+var csvAliases = map[string]string{
+	"due_date":     "due",
+	"scheduled_on": "scheduled",
+	"created_at":   "created",
+	"updated_at":   "updated",
+	"parent":       "parent_id",
+	"path":         "parent_path",
+	"name":         "title",
+	"tag":          "tags",
+}
+
+// ParseCSVColumns parses a comma-separated column list such as
+// "title,status,due".
+func ParseCSVColumns(spec string) ([]string, error) {
+	var cols []string
+	for _, c := range strings.Split(spec, ",") {
+		c = csvColumn(c)
+		if !isCSVColumn(c) {
+			return nil, fmt.Errorf("unknown column %q (want %s)", c, strings.Join(CSVColumns, ", "))
+		}
+		cols = append(cols, c)
+	}
+	return cols, nil
+}
+
+func csvColumn(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	name = strings.NewReplacer(" ", "_", "-", "_").Replace(name)
+	if alias, ok := csvAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func isCSVColumn(name string) bool {
+	for _, c := range CSVColumns {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// WriteCSV writes tasks as CSV with a header row. all holds every task in
+// the store and is used to build parent paths.
+func WriteCSV(w io.Writer, tasks, all []model.Task, columns []string) error {
+	byID := make(map[int]model.Task, len(all))
+	for _, t := range all {
+		byID[t.ID] = t
+	}
+	parentPath := func(t model.Task) string {
+		var titles []string
+		for pid := t.ParentID; pid != nil; {
+			p, ok := byID[*pid]
+			if !ok {
+				break
+			}
+			titles = append([]string{csvPathEscaper.Replace(p.Title)}, titles...)
+			pid = p.ParentID
+		}
+		return strings.Join(titles, CSVPathSeparator)
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(columns); err != nil {
+		return err
+	}
+	for _, t := range parentsFirst(tasks) {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			switch c {
+			case "id":
+				row[i] = strconv.Itoa(t.ID)
+			case "parent_id":
+				if t.ParentID != nil {
+					row[i] = strconv.Itoa(*t.ParentID)
+				}
+			case "parent_path":
+				row[i] = parentPath(t)
+			case "title":
+				row[i] = t.Title
+			case "status":
+				row[i] = t.Status.String()
+			case "priority":
+				row[i] = t.PriorityLetter()
+			case "due":
+				row[i] = deref(t.DueDate)
+			case "scheduled":
+				row[i] = deref(t.ScheduledOn)
+			case "tags":
+				names := make([]string, len(t.Tags))
+				for j, tag := range t.Tags {
+					names[j] = tag.Name
+				}
+				row[i] = strings.Join(names, ", ")
+			case "description":
+				row[i] = deref(t.Description)
+			case "created":
+				row[i] = t.CreatedAt.Format(time.RFC3339)
+			case "updated":
+				row[i] = t.UpdatedAt.Format(time.RFC3339)
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// isCSV reports whether the first line of text is a CSV header with a
+// title column and at least one other column.
+func isCSV(text string) bool {
+	header, _, _ := strings.Cut(strings.TrimLeft(text, "\ufeff\r\n"), "\n")
+	fields, err := csv.NewReader(strings.NewReader(header)).Read()
+	if err != nil || len(fields) < 2 {
+		return false
+	}
+	for _, f := range fields {
+		if csvColumn(f) == "title" {
+			return true
+		}
+	}
+	return false
+}
+
+// csvRow is a parsed CSV row before the hierarchy is rebuilt.
+type csvRow struct {
+	id, parentID string
+	parentPath   []string
+	node         *Node
+	children     []*csvRow
+}
+
+// ParseCSV reads a CSV file with a header row naming the columns listed in
+// CSVColumns; unknown columns are ignored and only title is required. The
+// hierarchy is rebuilt from parent_id, referring to the id column of
+// another row, or else from parent_path, the ancestor titles joined with
+// " > " in which "\>" and "\\" stand for ">" and "\". Ancestors named in a
+// path but missing from the file are created.
+func ParseCSV(text string) ([]Node, error) {
+	r := csv.NewReader(strings.NewReader(text))
+	r.FieldsPerRecord = -1
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("CSV parse error: %w", err)
+	}
+	if len(records) < 2 {
+		return nil, fmt.Errorf("no tasks found in CSV")
+	}
+
+	index := make(map[string]int)
+	for i, h := range records[0] {
+		h = csvColumn(strings.TrimPrefix(h, "\ufeff"))
+		if _, dup := index[h]; !dup && isCSVColumn(h) {
+			index[h] = i
+		}
+	}
+	if _, ok := index["title"]; !ok {
+		return nil, fmt.Errorf("CSV has no title column")
+	}
+	field := func(rec []string, col string) string {
+		i, ok := index[col]
+		if !ok || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var rows []*csvRow
+	for n, rec := range records[1:] {
+		line := n + 2
+		if strings.TrimSpace(strings.Join(rec, "")) == "" {
+			continue
+		}
+		node, err := csvNode(rec, field)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		row := &csvRow{id: field(rec, "id"), parentID: field(rec, "parent_id"), node: node}
+		for _, seg := range splitCSVPath(field(rec, "parent_path")) {
+			if seg = strings.TrimSpace(seg); seg != "" {
+				row.parentPath = append(row.parentPath, seg)
+			}
+		}
+		rows = append(rows, row)
+	}
+	if len(rows) == 0 {
+		return nil, fmt.Errorf("no tasks found in CSV")
+	}
+	return csvTree(rows)
+}
+
+// splitCSVPath splits a parent_path value on CSVPathSeparator and
+// unescapes the titles.
+func splitCSVPath(p string) []string {
+	var titles []string
+	var sb strings.Builder
+	for i := 0; i < len(p); i++ {
+		switch {
+		case p[i] == '\\' && i+1 < len(p) && (p[i+1] == '\\' || p[i+1] == '>'):
+			i++
+			sb.WriteByte(p[i])
+		case strings.HasPrefix(p[i:], CSVPathSeparator):
+			titles = append(titles, sb.String())
+			sb.Reset()
+			i += len(CSVPathSeparator) - 1
+		default:
+			sb.WriteByte(p[i])
+		}
+	}
+	return append(titles, sb.String())
+}
+
+func csvNode(rec []string, field func([]string, string) string) (*Node, error) {
+	n := &Node{
+		Title:       field(rec, "title"),
+		Description: field(rec, "description"),
+	}
+	if n.Title == "" {
+		return nil, fmt.Errorf("task title is required")
+	}
+	if s := field(rec, "status"); s != "" {
+		status, err := model.ParseTaskStatus(s)
+		if err != nil {
+			return nil, err
+		}
+		n.Status, n.StatusSet = status, true
+	}
+	if p := field(rec, "priority"); p != "" {
+		priority, err := model.ParsePriority(strings.ToUpper(p))
+		if err != nil {
+			return nil, err
+		}
+		n.Priority = priority
+	}
+	for col, dst := range map[string]*string{"due": &n.DueDate, "scheduled": &n.ScheduledOn} {
+		d := field(rec, col)
+		if d == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d); err != nil {
+			return nil, fmt.Errorf("invalid %s date %q (want YYYY-MM-DD)", col, d)
+		}
+		*dst = d
+	}
+	for _, tag := range strings.Split(field(rec, "tags"), ",") {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			n.Tags = append(n.Tags, tag)
+		}
+	}
+	return n, nil
+}
+
+// csvTree links rows to their parents and returns the root nodes.
+func csvTree(rows []*csvRow) ([]Node, error) {
+	byID := make(map[string]*csvRow)
+	for _, r := range rows {
+		if r.id == "" {
+			continue
+		}
+		if byID[r.id] != nil {
+			return nil, fmt.Errorf("duplicate id %q", r.id)
+		}
+		byID[r.id] = r
+	}
+
+	// byPath finds rows by their full title path, for parent_path.
+	byPath := make(map[string]*csvRow)
+	pathKey := func(titles []string) string { return strings.Join(titles, "\x00") }
+	for _, r := range rows {
+		if r.parentID != "" {
+			continue
+		}
+		key := pathKey(append(append([]string{}, r.parentPath...), r.node.Title))
+		if byPath[key] == nil {
+			byPath[key] = r
+		}
+	}
+
+	var roots []*csvRow
+	var parentOf func(path []string) *csvRow
+	parentOf = func(path []string) *csvRow {
+		if len(path) == 0 {
+			return nil
+		}
+		key := pathKey(path)
+		if p := byPath[key]; p != nil {
+			return p
+		}
+		// Create a placeholder for an ancestor missing from the file.
+		p := &csvRow{parentPath: path[:len(path)-1], node: &Node{Title: path[len(path)-1]}}
+		byPath[key] = p
+		if gp := parentOf(p.parentPath); gp != nil {
+			gp.children = append(gp.children, p)
+		} else {
+			roots = append(roots, p)
+		}
+		return p
+	}
+
+	for _, r := range rows {
+		var parent *csvRow
+		switch {
+		case r.parentID != "":
+			if parent = byID[r.parentID]; parent == nil {
+				return nil, fmt.Errorf("task %q: parent id %q not found", r.node.Title, r.parentID)
+			}
+		case len(r.parentPath) > 0:
+			parent = parentOf(r.parentPath)
+		}
+		if parent == nil {
+			roots = append(roots, r)
+		} else {
+			parent.children = append(parent.children, r)
+		}
+	}
+
+	// Rows whose parent ids form a cycle are not reachable from any root.
+	visited := make(map[*csvRow]bool, len(rows))
+	var build func(r *csvRow) Node
+	build = func(r *csvRow) Node {
+		visited[r] = true
+		n := *r.node
+		for _, c := range r.children {
+			n.Children = append(n.Children, build(c))
+		}
+		return n
+	}
+	nodes := make([]Node, len(roots))
+	for i, r := range roots {
+		nodes[i] = build(r)
+	}
+	for _, r := range rows {
+		if !visited[r] {
+			return nil, fmt.Errorf("task %q: parent ids form a cycle", r.node.Title)
+		}
+	}
+	return nodes, nil
+}
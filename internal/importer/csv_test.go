@@ -0,0 +1,53 @@
+package importer
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestCSVPathRoundTrip(t *testing.T) {
+	one, two := 1, 2
+	tasks := []model.Task{
+		{ID: 1, Title: "Q1 > Q2 review"},
+		{ID: 2, Title: `C:\work>tmp`, ParentID: &one},
+		{ID: 3, Title: "Leaf", ParentID: &two},
+	}
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, tasks, tasks, []string{"parent_path", "title"}); err != nil {
+		t.Fatal(err)
+	}
+	if want := `Q1 \> Q2 review > C:\\work\>tmp`; !strings.Contains(buf.String(), want) {
+		t.Errorf("CSV = %q, want a parent_path of %q", buf.String(), want)
+	}
+
+	nodes, err := ParseCSV(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for n := nodes; len(n) > 0; n = n[0].Children {
+		if len(n) != 1 {
+			t.Fatalf("%d nodes at one level, want 1", len(n))
+		}
+		titles = append(titles, n[0].Title)
+	}
+	if got := strings.Join(titles, " | "); got != `Q1 > Q2 review | C:\work>tmp | Leaf` {
+		t.Errorf("hierarchy = %s", got)
+	}
+}
+
+func TestCSVPathSeparator(t *testing.T) {
+	nodes, err := ParseCSV("parent_path,title\n,a>b\na>b,c\nx > y,z\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 || nodes[0].Title != "a>b" || len(nodes[0].Children) != 1 {
+		t.Fatalf("nodes = %+v, want c below a>b", nodes)
+	}
+	if nodes[1].Title != "x" || len(nodes[1].Children) != 1 || nodes[1].Children[0].Title != "y" {
+		t.Errorf("nodes = %+v, want z below x > y", nodes[1])
+	}
+}
@@ -0,0 +1,348 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// icalUIDSuffix makes the UIDs of exported tasks globally unique.
+const icalUIDSuffix = "@flow"
+
+// isICal reports whether text looks like an iCalendar file.
+func isICal(text string) bool {
+	return strings.HasPrefix(strings.TrimSpace(strings.ToUpper(text)), "BEGIN:VCALENDAR")
+}
+
+// WriteICal writes tasks as an iCalendar file with one VTODO per task.
+// Parents are linked with RELATED-TO, tags become CATEGORIES and the
+// priority maps A to I onto PRIORITY 1 to 9.
+func WriteICal(w io.Writer, tasks []model.Task) error {
+	bw := bufio.NewWriter(w)
+	line := func(name, value string) {
+		writeICalLine(bw, name+":"+value)
+	}
+	date := func(d string) string {
+		return strings.ReplaceAll(d, "-", "")
+	}
+	stamp := func(t time.Time) string {
+		return t.UTC().Format("20060102T150405Z")
+	}
+
+	line("BEGIN", "VCALENDAR")
+	line("VERSION", "2.0")
+	line("PRODID", "-//flow//flow tasks//EN")
+	for _, t := range tasks {
+		line("BEGIN", "VTODO")
+		line("UID", icalUID(t.ID))
+		line("DTSTAMP", stamp(t.UpdatedAt))
+		line("CREATED", stamp(t.CreatedAt))
+		line("LAST-MODIFIED", stamp(t.UpdatedAt))
+		line("SUMMARY", icalEscape(t.Title))
+		if t.Description != nil && *t.Description != "" {
+			line("DESCRIPTION", icalEscape(*t.Description))
+		}
+		if t.ScheduledOn != nil {
+			line("DTSTART;VALUE=DATE", date(*t.ScheduledOn))
+		}
+		if t.DueDate != nil {
+			line("DUE;VALUE=DATE", date(*t.DueDate))
+		}
+		switch t.Status {
+		case model.StatusInProgress:
+			line("STATUS", "IN-PROCESS")
+		case model.StatusCompleted:
+			line("STATUS", "COMPLETED")
+			line("COMPLETED", stamp(t.UpdatedAt))
+		default:
+			line("STATUS", "NEEDS-ACTION")
+		}
+		if t.Priority > 0 {
+			line("PRIORITY", strconv.Itoa(min(t.Priority, 9)))
+		}
+		if t.ParentID != nil {
+			line("RELATED-TO;RELTYPE=PARENT", icalUID(*t.ParentID))
+		}
+		if len(t.Tags) > 0 {
+			names := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				names[i] = icalEscape(tag.Name)
+			}
+			line("CATEGORIES", strings.Join(names, ","))
+		}
+		line("END", "VTODO")
+	}
+	line("END", "VCALENDAR")
+	return bw.Flush()
+}
+
+func icalUID(id int) string {
+	return "task-" + strconv.Itoa(id) + icalUIDSuffix
+}
+
+// writeICalLine writes a content line folded at 75 octets, as RFC 5545
+// requires, without splitting UTF-8 sequences.
+func writeICalLine(w *bufio.Writer, s string) {
+	limit := 75
+	for len(s) > limit {
+		cut := limit
+		for cut > 0 && s[cut]&0xC0 == 0x80 {
+			cut--
+		}
+		w.WriteString(s[:cut] + "\r\n ")
+		s = s[cut:]
+		limit = 74 // continuation lines start with a space
+	}
+	w.WriteString(s + "\r\n")
+}
+
+func icalEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func icalUnescape(s string) string {
+	return strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n").Replace(s)
+}
+
+// icalProp is a parsed content line.
+type icalProp struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// icalTodo is a VTODO before the hierarchy is rebuilt.
+type icalTodo struct {
+	uid    string
+	parent string
+	node   Node
+}
+
+// ParseICal reads the VTODO components of an iCalendar file; other
+// components such as VEVENT are skipped. DUE and DTSTART set the due and
+// scheduled dates, STATUS the status, CATEGORIES the tags and PRIORITY 1
+// to 9 the priorities A to I. RELATED-TO links with RELTYPE=PARENT (the
+// default) rebuild the hierarchy.
+func ParseICal(text string) ([]Node, error) {
+	var todos []*icalTodo
+	var cur *icalTodo
+	depth := 0 // nesting inside the current VTODO, e.g. VALARM
+
+	for i, raw := range unfoldICal(text) {
+		if raw == "" {
+			continue
+		}
+		p, err := parseICalLine(raw)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		switch {
+		case p.name == "BEGIN" && strings.EqualFold(p.value, "VTODO") && cur == nil:
+			cur = &icalTodo{}
+		case cur == nil:
+		case p.name == "BEGIN":
+			depth++
+		case p.name == "END" && depth > 0:
+			depth--
+		case p.name == "END" && strings.EqualFold(p.value, "VTODO"):
+			if cur.node.Title == "" {
+				return nil, fmt.Errorf("line %d: VTODO without SUMMARY", i+1)
+			}
+			if cur.uid == "" {
+				cur.uid = fmt.Sprintf("#%d", len(todos))
+			}
+			todos = append(todos, cur)
+			cur = nil
+		case depth == 0:
+			if err := applyICalProp(cur, p); err != nil {
+				return nil, fmt.Errorf("line %d: %w", i+1, err)
+			}
+		}
+	}
+	if len(todos) == 0 {
+		return nil, fmt.Errorf("no VTODO entries found in iCalendar data")
+	}
+	return icalTree(todos), nil
+}
+
+func applyICalProp(t *icalTodo, p icalProp) error {
+	switch p.name {
+	case "UID":
+		t.uid = p.value
+	case "SUMMARY":
+		t.node.Title = strings.TrimSpace(icalUnescape(p.value))
+	case "DESCRIPTION":
+		t.node.Description = icalUnescape(p.value)
+	case "DUE", "DTSTART":
+		d, err := icalDate(p)
+		if err != nil {
+			return fmt.Errorf("%s: %w", p.name, err)
+		}
+		if p.name == "DUE" {
+			t.node.DueDate = d
+		} else {
+			t.node.ScheduledOn = d
+		}
+	case "STATUS":
+		switch strings.ToUpper(p.value) {
+		case "IN-PROCESS":
+			t.node.Status = model.StatusInProgress
+		case "COMPLETED", "CANCELLED":
+			t.node.Status = model.StatusCompleted
+		}
+	case "COMPLETED":
+		t.node.Status = model.StatusCompleted
+	case "PRIORITY":
+		n, err := strconv.Atoi(p.value)
+		if err != nil || n < 0 || n > 9 {
+			return fmt.Errorf("invalid PRIORITY %q", p.value)
+		}
+		t.node.Priority = n
+	case "CATEGORIES":
+		for _, c := range splitICalList(p.value) {
+			if c = strings.TrimSpace(icalUnescape(c)); c != "" {
+				t.node.Tags = append(t.node.Tags, c)
+			}
+		}
+	case "RELATED-TO":
+		if rel := strings.ToUpper(p.params["RELTYPE"]); rel == "" || rel == "PARENT" {
+			t.parent = p.value
+		}
+	}
+	return nil
+}
+
+// icalTree nests todos under their RELATED-TO parents. Links to unknown
+// UIDs or forming a cycle are ignored.
+func icalTree(todos []*icalTodo) []Node {
+	byUID := make(map[string]*icalTodo, len(todos))
+	for _, t := range todos {
+		byUID[t.uid] = t
+	}
+	children := make(map[string][]*icalTodo)
+	var roots []*icalTodo
+	for _, t := range todos {
+		if t.parent != "" && byUID[t.parent] != nil && !icalCycle(t, byUID) {
+			children[t.parent] = append(children[t.parent], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+	var build func(t *icalTodo) Node
+	build = func(t *icalTodo) Node {
+		n := t.node
+		for _, c := range children[t.uid] {
+			n.Children = append(n.Children, build(c))
+		}
+		return n
+	}
+	nodes := make([]Node, len(roots))
+	for i, r := range roots {
+		nodes[i] = build(r)
+	}
+	return nodes
+}
+
+// icalCycle reports whether following parents from t leads back to t.
+func icalCycle(t *icalTodo, byUID map[string]*icalTodo) bool {
+	seen := map[string]bool{t.uid: true}
+	for p := byUID[t.parent]; p != nil; p = byUID[p.parent] {
+		if seen[p.uid] {
+			return true
+		}
+		seen[p.uid] = true
+	}
+	return false
+}
+
+// unfoldICal splits text into content lines, joining folded continuations.
+func unfoldICal(text string) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if len(lines) > 0 && (strings.HasPrefix(l, " ") || strings.HasPrefix(l, "\t")) {
+			lines[len(lines)-1] += l[1:]
+			continue
+		}
+		lines = append(lines, l)
+	}
+	return lines
+}
+
+// parseICalLine splits "NAME;PARAM=VALUE:value" into its parts.
+func parseICalLine(line string) (icalProp, error) {
+	// The value starts at the first colon outside a quoted parameter.
+	quoted := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			quoted = !quoted
+		} else if r == ':' && !quoted {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return icalProp{}, fmt.Errorf("malformed content line %q", line)
+	}
+	head := strings.Split(line[:colon], ";")
+	p := icalProp{name: strings.ToUpper(head[0]), params: make(map[string]string), value: line[colon+1:]}
+	for _, param := range head[1:] {
+		k, v, _ := strings.Cut(param, "=")
+		p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+	}
+	return p, nil
+}
+
+// icalDate returns the local calendar date of a DATE or DATE-TIME value.
+// UTC times such as 20261031T230000Z and times with a known TZID are
+// converted to the local time zone; floating times are local already.
+func icalDate(p icalProp) (string, error) {
+	v := p.value
+	if len(v) == 8 {
+		d, err := time.Parse("20060102", v)
+		if err != nil {
+			return "", fmt.Errorf("invalid date %q", v)
+		}
+		return d.Format("2006-01-02"), nil
+	}
+	loc := time.Local
+	if tz := p.params["TZID"]; tz != "" {
+		if l, err := time.LoadLocation(tz); err == nil {
+			loc = l
+		}
+	}
+	layout := "20060102T150405"
+	if strings.HasSuffix(v, "Z") {
+		layout, loc = layout+"Z", time.UTC
+	}
+	t, err := time.ParseInLocation(layout, v, loc)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", v)
+	}
+	return t.Local().Format("2006-01-02"), nil
+}
+
+// splitICalList splits a comma-separated value, honoring "\," escapes.
+func splitICalList(v string) []string {
+	var parts []string
+	var sb strings.Builder
+	for i := 0; i < len(v); i++ {
+		switch {
+		case v[i] == '\\' && i+1 < len(v):
+			sb.WriteByte(v[i])
+			sb.WriteByte(v[i+1])
+			i++
+		case v[i] == ',':
+			parts = append(parts, sb.String())
+			sb.Reset()
+		default:
+			sb.WriteByte(v[i])
+		}
+	}
+	return append(parts, sb.String())
+}
@@ -0,0 +1,69 @@
+package importer
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestICalRoundTrip(t *testing.T) {
+	now := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	due, scheduled, desc := "2026-11-01", "2026-10-20", "Line one\nLine two; with, punctuation"
+	one := 1
+	tasks := []model.Task{
+		{ID: 1, Title: "Trip; Paris, France", Description: &desc, DueDate: &due, ScheduledOn: &scheduled,
+			Priority: 2, Status: model.StatusInProgress, CreatedAt: now, UpdatedAt: now,
+			Tags: []model.Tag{{Name: "travel"}, {Name: "home/plans"}}},
+		{ID: 2, Title: strings.Repeat("Book flights ", 10), ParentID: &one,
+			Status: model.StatusCompleted, CreatedAt: now, UpdatedAt: now},
+	}
+	var buf bytes.Buffer
+	if err := WriteICal(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := ParseICal(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Children) != 1 {
+		t.Fatalf("nodes = %+v, want one task with one sub-task", nodes)
+	}
+	trip, flights := nodes[0], nodes[0].Children[0]
+	if trip.Title != tasks[0].Title || trip.Description != desc || trip.DueDate != due ||
+		trip.ScheduledOn != scheduled || trip.Priority != 2 || trip.Status != model.StatusInProgress ||
+		!slices.Equal(trip.Tags, []string{"travel", "home/plans"}) {
+		t.Errorf("parent = %+v", trip)
+	}
+	if flights.Title != strings.TrimSpace(tasks[1].Title) || flights.Status != model.StatusCompleted {
+		t.Errorf("sub-task = %+v", flights)
+	}
+}
+
+func TestICalDateTimes(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+9", 9*60*60)
+	t.Cleanup(func() { time.Local = local })
+
+	for _, tc := range []struct{ line, want string }{
+		{"DUE;VALUE=DATE:20261031", "2026-10-31"},
+		{"DUE:20261031T230000Z", "2026-11-01"},
+		{"DUE:20261031T230000", "2026-10-31"},
+	} {
+		text := "BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:a\r\n" + tc.line + "\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"
+		nodes, err := ParseICal(text)
+		if err != nil {
+			t.Errorf("%s: %v", tc.line, err)
+			continue
+		}
+		if nodes[0].DueDate != tc.want {
+			t.Errorf("%s: due %s, want %s", tc.line, nodes[0].DueDate, tc.want)
+		}
+	}
+	if _, err := ParseICal("BEGIN:VCALENDAR\r\nBEGIN:VTODO\r\nSUMMARY:a\r\nDUE:2026103\r\nEND:VTODO\r\nEND:VCALENDAR\r\n"); err == nil {
+		t.Error("ParseICal accepted a malformed DUE")
+	}
+}
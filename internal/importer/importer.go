@@ -0,0 +1,322 @@
+// Package importer converts between tasks in a TaskStore and external
+// formats: YAML, Markdown checklists, todo.txt, iCalendar, CSV, Taskwarrior
+// and flow exports.
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// Node is a task parsed from an import format, before it is stored.
+type Node struct {
+	// ID is a key given by the source, such as the YAML id. DependsOn
+	// refers to it, and Merge matches IDs of the form "flow-12" to existing
+	// tasks; other IDs, plain numbers included, are local to the source.
+	ID          string
+	Title       string
+	Description string
+	Status      model.TaskStatus
+	StatusSet   bool // Status was given by the source, not defaulted
+	Priority    int
+	DueDate     string
+	ScheduledOn string
+	Tags        []string
+	TagColors   map[string]string // colors for some of Tags, by name
+	DependsOn   []string          // IDs of other nodes in the same import
+	Children    []Node
+}
+
+// Format names a text format understood by ImportText.
+type Format string
+
+const (
+	FormatYAML        Format = "YAML"
+	FormatMarkdown    Format = "Markdown"
+	FormatTodoTxt     Format = "todo.txt"
+	FormatICal        Format = "iCalendar"
+	FormatCSV         Format = "CSV"
+	FormatTaskwarrior Format = "Taskwarrior"
+)
+
+// checklistLine matches a Markdown list item with a checkbox.
+var checklistLine = regexp.MustCompile(`^\s*[-*+]\s+\[[ xX-]\]`)
+
+// yamlTasksKey matches the top-level key of the YAML import format.
+var yamlTasksKey = regexp.MustCompile(`(?m)^tasks:`)
+
+// Detect guesses the format of text: iCalendar if it starts with
+// BEGIN:VCALENDAR, Taskwarrior for JSON with uuid fields, CSV if the first
+// line is a header with a title column, YAML if it has a top-level "tasks:"
+// key, a Markdown checklist if any line is a checkbox list item, todo.txt if
+// it uses todo.txt markers such as "(A)", "x ", "+project" or "@context",
+// and YAML otherwise. YAML is checked before Markdown because descriptions
+// may contain checklists.
+func Detect(text string) Format {
+	if isICal(text) {
+		return FormatICal
+	}
+	if isTaskwarrior(text) {
+		return FormatTaskwarrior
+	}
+	if isCSV(text) {
+		return FormatCSV
+	}
+	if yamlTasksKey.MatchString(text) {
+		return FormatYAML
+	}
+	for _, line := range strings.Split(text, "\n") {
+		if checklistLine.MatchString(line) {
+			return FormatMarkdown
+		}
+	}
+	if isTodoTxt(text) {
+		return FormatTodoTxt
+	}
+	return FormatYAML
+}
+
+// Parse parses text in the given format.
+func Parse(text string, format Format) ([]Node, error) {
+	switch format {
+	case FormatMarkdown:
+		return ParseMarkdown(text)
+	case FormatYAML:
+		return ParseYAML(text)
+	case FormatTodoTxt:
+		return ParseTodoTxt(text)
+	case FormatICal:
+		return ParseICal(text)
+	case FormatCSV:
+		return ParseCSV(text)
+	case FormatTaskwarrior:
+		return ParseTaskwarrior(text)
+	}
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+// StripCodeBlock returns the contents of the fenced code blocks in s, as
+// found in a chat reply, or s itself if it has none. An unterminated block
+// runs to the end of s.
+func StripCodeBlock(s string) string {
+	lines := strings.Split(s, "\n")
+	var result []string
+	inBlock := false
+	for _, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !inBlock && strings.HasPrefix(trimmed, "```") {
+			inBlock = true
+			continue
+		}
+		if inBlock && trimmed == "```" {
+			inBlock = false
+			continue
+		}
+		if inBlock {
+			result = append(result, line)
+		}
+	}
+	// If no code block was found, return original
+	if len(result) == 0 {
+		return s
+	}
+	return strings.Join(result, "\n")
+}
+
+// ImportText detects the format of text, parses it and creates the tasks
+// under parentID (nil for root tasks) in a single transaction. It returns
+// the detected format and the number of tasks created.
+func ImportText(s *store.TaskStore, text string, parentID *int) (Format, int, error) {
+	format := Detect(text)
+	nodes, err := Parse(text, format)
+	if err != nil {
+		return format, 0, err
+	}
+	n, err := Create(s, nodes, parentID)
+	return format, n, err
+}
+
+// Count returns the number of tasks in nodes, including all descendants.
+func Count(nodes []Node) int {
+	n := len(nodes)
+	for _, c := range nodes {
+		n += Count(c.Children)
+	}
+	return n
+}
+
+// Create stores nodes and their children under parentID (nil for root
+// tasks) and returns the number of tasks created. The nodes are validated
+// first and written in a single transaction, so on error nothing is
+// created and the count is 0.
+func Create(s *store.TaskStore, nodes []Node, parentID *int) (int, error) {
+	if err := Validate(nodes); err != nil {
+		return 0, err
+	}
+	count := 0
+	err := s.WithTx(func(tx *store.TaskStore) error {
+		ids := make(map[string]int)
+		for _, n := range nodes {
+			c, err := createNode(tx, n, parentID, ids)
+			count += c
+			if err != nil {
+				return err
+			}
+		}
+		return linkDependencies(tx, nodes, ids)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// Validate checks nodes and all their descendants for missing titles,
+// dates that are not YYYY-MM-DD, malformed tag names and colors, and
+// depends_on links to unknown ids or forming a cycle.
+func Validate(nodes []Node) error {
+	var problems []string
+	flat := flatten(nodes)
+	for i, msgs := range listProblems(flat) {
+		for _, msg := range msgs {
+			problems = append(problems, problemText(flat[i].Title, msg))
+		}
+	}
+	switch len(problems) {
+	case 0:
+		return nil
+	case 1:
+		return errors.New(problems[0])
+	}
+	return fmt.Errorf("%d problems: %s", len(problems), strings.Join(problems, "; "))
+}
+
+// createNode stores n and its children, recording the task IDs of nodes
+// with an ID in ids.
+func createNode(s *store.TaskStore, n Node, parentID *int, ids map[string]int) (int, error) {
+	task, err := createTask(s, n, parentID)
+	if err != nil {
+		return 0, err
+	}
+	count := 1
+	if n.ID != "" {
+		ids[n.ID] = task.ID
+	}
+
+	for _, child := range n.Children {
+		id := task.ID
+		c, err := createNode(s, child, &id, ids)
+		count += c
+		if err != nil {
+			return count, err
+		}
+	}
+
+	return count, nil
+}
+
+// createTask stores n without its children.
+func createTask(s *store.TaskStore, n Node, parentID *int) (model.Task, error) {
+	task, err := s.Add(n.Title, parentID)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("add task %q: %w", n.Title, err)
+	}
+
+	if n.Description != "" {
+		desc := n.Description
+		if err := s.UpdateDescription(task.ID, &desc); err != nil {
+			return task, fmt.Errorf("set description for %q: %w", n.Title, err)
+		}
+	}
+
+	if n.Status != model.StatusNotStarted {
+		if err := s.UpdateStatus(task.ID, n.Status); err != nil {
+			return task, fmt.Errorf("set status for %q: %w", n.Title, err)
+		}
+	}
+
+	if n.Priority != 0 {
+		if err := s.SetPriority(task.ID, n.Priority); err != nil {
+			return task, fmt.Errorf("set priority for %q: %w", n.Title, err)
+		}
+	}
+
+	if n.DueDate != "" {
+		dd := n.DueDate
+		if err := s.SetDueDate(task.ID, &dd); err != nil {
+			return task, fmt.Errorf("set due date for %q: %w", n.Title, err)
+		}
+	}
+
+	if n.ScheduledOn != "" {
+		so := n.ScheduledOn
+		if err := s.SetScheduledOn(task.ID, &so); err != nil {
+			return task, fmt.Errorf("set scheduled date for %q: %w", n.Title, err)
+		}
+	}
+
+	if len(n.Tags) > 0 {
+		if err := assignTags(s, task.ID, n.Tags, n.TagColors); err != nil {
+			return task, fmt.Errorf("assign tags for %q: %w", n.Title, err)
+		}
+	}
+	return task, nil
+}
+
+// linkDependencies adds the depends_on links of nodes and their children,
+// looking up the created or merged tasks in ids.
+func linkDependencies(s *store.TaskStore, nodes []Node, ids map[string]int) error {
+	for _, n := range nodes {
+		for _, dep := range n.DependsOn {
+			if err := s.AddDependency(ids[n.ID], ids[dep]); err != nil {
+				return fmt.Errorf("task %q depends on %q: %w", n.Title, dep, err)
+			}
+		}
+		if err := linkDependencies(s, n.Children, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// assignTags assigns tags by name, creating missing ones. A color in colors
+// is given to the tag whether it is new or not.
+func assignTags(s *store.TaskStore, taskID int, tagNames []string, colors map[string]string) error {
+	existingTags, err := s.ListTags()
+	if err != nil {
+		return err
+	}
+
+	tagMap := make(map[string]model.Tag)
+	for _, t := range existingTags {
+		tagMap[t.Name] = t
+	}
+
+	for _, name := range tagNames {
+		tag, exists := tagMap[name]
+		if !exists {
+			// EnsureTag also creates any missing parent tags of a nested name.
+			if tag, err = s.EnsureTag(name, model.PaletteColor(len(tagMap))); err != nil {
+				return fmt.Errorf("create tag %q: %w", name, err)
+			}
+			tagMap[name] = tag
+		}
+		if c := colors[name]; c != "" && c != tag.Color {
+			if err := s.SetTagColor(tag.ID, c); err != nil {
+				return fmt.Errorf("set color of tag %q: %w", name, err)
+			}
+			tag.Color = c
+			tagMap[name] = tag
+		}
+		tagID := tag.ID
+		if err := s.AssignTag(taskID, tagID); err != nil {
+			return fmt.Errorf("assign tag %q: %w", name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package importer
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// newTestStore opens a store in a temporary directory.
+func newTestStore(t *testing.T) *store.TaskStore {
+	t.Helper()
+	s, err := store.NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestDetect(t *testing.T) {
+	for _, tc := range []struct {
+		name, text string
+		want       Format
+	}{
+		{"checklist", "- [ ] a\n  - [x] b\n", FormatMarkdown},
+		{"yaml", "tasks:\n  - title: a\n", FormatYAML},
+		{"yaml with a checklist", "tasks:\n  - title: a\n    description: |\n      - [ ] step one\n      - [x] step two\n", FormatYAML},
+		{"todo.txt", "(A) call mom +family\nx 2026-10-01 pay rent\n", FormatTodoTxt},
+		{"csv", "title,status\na,completed\n", FormatCSV},
+		{"ical", "BEGIN:VCALENDAR\nEND:VCALENDAR\n", FormatICal},
+		{"taskwarrior", `[{"uuid":"a","description":"A"}]`, FormatTaskwarrior},
+		{"plain yaml", "- title: a\n", FormatYAML},
+	} {
+		if got := Detect(tc.text); got != tc.want {
+			t.Errorf("%s: Detect = %s, want %s", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package importer
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// listItem matches a Markdown list item with an optional checkbox:
+// indentation, bullet, checkbox state and text.
+var listItem = regexp.MustCompile(`^(\s*)[-*+]\s+(?:\[([ xX-])\]\s*)?(.*)$`)
+
+// ParseMarkdown parses a nested Markdown checklist such as the one copied
+// with `c`:
+//
+//	## Work
+//	- [ ] Write report #work due:2026-11-01
+//	  - [-] Collect numbers
+//	  - [x] Draft outline
+//
+// "[ ]", "[-]" and "[x]" map to not started, in progress and completed;
+// items without a checkbox are not started. "#tag" and "due:YYYY-MM-DD"
+// words are taken out of the title. Non-list lines indented below an item
+// become its description; headings and other lines are ignored.
+func ParseMarkdown(text string) ([]Node, error) {
+	type frame struct {
+		indent int
+		node   *Node
+	}
+	var roots []*Node
+	var stack []frame
+	var last *Node
+	lastIndent := -1
+
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		lineNo := i + 1
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		indent := indentWidth(line)
+		m := listItem.FindStringSubmatch(line)
+		if m == nil {
+			if last != nil && indent > lastIndent {
+				last.Description = strings.TrimPrefix(last.Description+"\n"+strings.TrimSpace(line), "\n")
+			}
+			continue
+		}
+
+		n, err := parseMarkdownItem(m[2], m[3])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		node := &n
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1].node
+			parent.Children = append(parent.Children, n)
+			node = &parent.Children[len(parent.Children)-1]
+		}
+		stack = append(stack, frame{indent: indent, node: node})
+		last, lastIndent = node, indent
+	}
+
+	if len(roots) == 0 {
+		return nil, fmt.Errorf("no tasks found in Markdown")
+	}
+	nodes := make([]Node, len(roots))
+	for i, r := range roots {
+		nodes[i] = *r
+	}
+	return nodes, nil
+}
+
+// parseMarkdownItem builds a node from a checkbox state and item text.
+func parseMarkdownItem(check, text string) (Node, error) {
+	n := Node{StatusSet: check != ""}
+	switch check {
+	case "-":
+		n.Status = model.StatusInProgress
+	case "x", "X":
+		n.Status = model.StatusCompleted
+	}
+
+	var words []string
+	for _, w := range strings.Fields(text) {
+		switch {
+		case strings.HasPrefix(w, "due:"):
+			d := strings.TrimPrefix(w, "due:")
+			if _, err := time.Parse("2006-01-02", d); err != nil {
+				return Node{}, fmt.Errorf("invalid due date %q (want YYYY-MM-DD)", d)
+			}
+			n.DueDate = d
+		case isHashTag(w):
+			n.Tags = append(n.Tags, w[1:])
+		default:
+			words = append(words, w)
+		}
+	}
+	n.Title = strings.Join(words, " ")
+	if n.Title == "" {
+		return Node{}, fmt.Errorf("task title is required")
+	}
+	return n, nil
+}
+
+// isHashTag reports whether w is a "#tag" word. Words such as "#1" are
+// kept in the title.
+func isHashTag(w string) bool {
+	if len(w) < 2 || w[0] != '#' {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(w[1:])
+	return !unicode.IsDigit(r) && r != '#'
+}
+
+// indentWidth returns the width of the leading whitespace of line, counting
+// a tab as four columns.
+func indentWidth(line string) int {
+	width := 0
+	for _, r := range line {
+		switch r {
+		case ' ':
+			width++
+		case '\t':
+			width += 4
+		default:
+			return width
+		}
+	}
+	return width
+}
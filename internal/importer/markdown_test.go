@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	text := strings.Join([]string{
+		"## Work",
+		"- [ ] Write report #work due:2026-11-01",
+		"  Numbers from Q3",
+		"  - [-] Collect numbers",
+		"    - [x] Ask finance",
+		"  - [x] Draft outline #1",
+		"* Plain item",
+	}, "\n")
+	nodes, err := ParseMarkdown(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 2 {
+		t.Fatalf("%d roots, want 2", len(nodes))
+	}
+	report := nodes[0]
+	if report.Title != "Write report" || report.DueDate != "2026-11-01" ||
+		len(report.Tags) != 1 || report.Tags[0] != "work" || report.Description != "Numbers from Q3" {
+		t.Errorf("root = %+v", report)
+	}
+	if len(report.Children) != 2 {
+		t.Fatalf("%d children, want 2", len(report.Children))
+	}
+	collect, outline := report.Children[0], report.Children[1]
+	if collect.Status != model.StatusInProgress || len(collect.Children) != 1 ||
+		collect.Children[0].Title != "Ask finance" || collect.Children[0].Status != model.StatusCompleted {
+		t.Errorf("first child = %+v", collect)
+	}
+	if outline.Title != "Draft outline #1" || outline.Status != model.StatusCompleted {
+		t.Errorf("second child = %+v", outline)
+	}
+	if nodes[1].Title != "Plain item" || nodes[1].Status != model.StatusNotStarted {
+		t.Errorf("second root = %+v", nodes[1])
+	}
+}
+
+func TestParseMarkdownErrors(t *testing.T) {
+	for _, text := range []string{
+		"# Only a heading",
+		"- [ ] Report due:friday",
+		"- [ ] #work",
+	} {
+		if _, err := ParseMarkdown(text); err == nil {
+			t.Errorf("ParseMarkdown(%q) succeeded", text)
+		}
+	}
+}
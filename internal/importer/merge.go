@@ -0,0 +1,177 @@
+package importer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// MergeResult counts the tasks touched by Merge.
+type MergeResult struct {
+	Created   int
+	Updated   int
+	Unchanged int
+}
+
+// Merge stores nodes under parentID like Create, but first matches each
+// node to an existing task with the same parent: by ID when the node has an
+// ID such as "flow-12" naming one of those tasks, otherwise by title. A
+// matched task takes the description, status, priority and dates the node
+// sets, an explicit not_started status included, and gains its tags and tag
+// colors; existing tags are kept. Its children are merged the same
+// way. Unmatched nodes are created, then depends_on links are added. The
+// nodes are validated first and written in a single transaction.
+func Merge(s *store.TaskStore, nodes []Node, parentID *int) (MergeResult, error) {
+	if err := Validate(nodes); err != nil {
+		return MergeResult{}, err
+	}
+	var res MergeResult
+	err := s.WithTx(func(tx *store.TaskStore) error {
+		ids := make(map[string]int)
+		if err := mergeNodes(tx, nodes, parentID, &res, ids); err != nil {
+			return err
+		}
+		return linkDependencies(tx, nodes, ids)
+	})
+	if err != nil {
+		return MergeResult{}, err
+	}
+	return res, nil
+}
+
+func mergeNodes(s *store.TaskStore, nodes []Node, parentID *int, res *MergeResult, ids map[string]int) error {
+	existing, err := childTasks(s, parentID)
+	if err != nil {
+		return err
+	}
+	used := make(map[int]bool)
+	for _, n := range nodes {
+		t, ok := matchTask(existing, used, n)
+		if !ok {
+			c, err := createNode(s, n, parentID, ids)
+			res.Created += c
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		used[t.ID] = true
+		if n.ID != "" {
+			ids[n.ID] = t.ID
+		}
+		changed, err := updateFromNode(s, t, n)
+		if err != nil {
+			return fmt.Errorf("update %q: %w", t.Title, err)
+		}
+		if changed {
+			res.Updated++
+		} else {
+			res.Unchanged++
+		}
+		id := t.ID
+		if err := mergeNodes(s, n.Children, &id, res, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// childTasks returns the sub-tasks of parentID, or the root tasks if it is
+// nil.
+func childTasks(s *store.TaskStore, parentID *int) ([]model.Task, error) {
+	if parentID != nil {
+		return s.ChildrenOf(*parentID)
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	var roots []model.Task
+	for _, t := range all {
+		if t.ParentID == nil {
+			roots = append(roots, t)
+		}
+	}
+	return roots, nil
+}
+
+// matchTask finds the task in candidates that n refers to, skipping tasks
+// already matched by an earlier node.
+func matchTask(candidates []model.Task, used map[int]bool, n Node) (model.Task, bool) {
+	if id := nodeTaskID(n); id != 0 {
+		for _, t := range candidates {
+			if t.ID == id && !used[t.ID] {
+				return t, true
+			}
+		}
+	}
+	title := strings.TrimSpace(n.Title)
+	for _, t := range candidates {
+		if !used[t.ID] && strings.EqualFold(strings.TrimSpace(t.Title), title) {
+			return t, true
+		}
+	}
+	return model.Task{}, false
+}
+
+// updateFromNode applies the fields n sets to t and reports whether
+// anything changed.
+func updateFromNode(s *store.TaskStore, t model.Task, n Node) (bool, error) {
+	var p store.TaskPatch
+	changed := false
+	// Titles differing only in case or spacing matched; only an ID match
+	// renames the task.
+	if !strings.EqualFold(strings.TrimSpace(n.Title), strings.TrimSpace(t.Title)) {
+		p.Title = &n.Title
+		changed = true
+	}
+	if n.Description != "" && n.Description != deref(t.Description) {
+		p.Description = &n.Description
+		changed = true
+	}
+	if (n.StatusSet || n.Status != model.StatusNotStarted) && n.Status != t.Status {
+		p.Status = &n.Status
+		changed = true
+	}
+	if n.DueDate != "" && n.DueDate != deref(t.DueDate) {
+		p.DueDate = &n.DueDate
+		changed = true
+	}
+	if n.ScheduledOn != "" && n.ScheduledOn != deref(t.ScheduledOn) {
+		p.ScheduledOn = &n.ScheduledOn
+		changed = true
+	}
+	if changed {
+		if _, err := s.UpdateTask(t.ID, 0, p); err != nil {
+			return false, err
+		}
+	}
+
+	if n.Priority != 0 && n.Priority != t.Priority {
+		if err := s.SetPriority(t.ID, n.Priority); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+
+	colors := make(map[string]string, len(t.Tags))
+	for _, tag := range t.Tags {
+		colors[tag.Name] = tag.Color
+	}
+	retag := false
+	for _, name := range n.Tags {
+		color, has := colors[name]
+		if c := n.TagColors[name]; !has || c != "" && c != color {
+			retag = true
+		}
+	}
+	if retag {
+		if err := assignTags(s, t.ID, n.Tags, n.TagColors); err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	return changed, nil
+}
@@ -0,0 +1,123 @@
+package importer
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestMergeMatchesTaskRefs(t *testing.T) {
+	s := newTestStore(t)
+	first, err := s.Add("Existing", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A plain number only names a task within the document.
+	nodes, err := ParseYAML("tasks:\n  - id: " + strconv.Itoa(first.ID) + "\n    title: New\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := Merge(s, nodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 1 || res.Updated != 0 {
+		t.Errorf("merge with a plain id = %+v, want one task created", res)
+	}
+
+	nodes, err = ParseYAML("tasks:\n  - id: flow-" + strconv.Itoa(first.ID) + "\n    title: Renamed\n    status: completed\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err = Merge(s, nodes, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Created != 0 || res.Updated != 1 {
+		t.Errorf("merge with a flow- id = %+v, want one task updated", res)
+	}
+	got, err := s.GetByID(first.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Title != "Renamed" || got.Status != model.StatusCompleted {
+		t.Errorf("task = %q %v, want it renamed and completed", got.Title, got.Status)
+	}
+}
+
+func TestMergeReopens(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.Add("Done", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.UpdateStatus(task.ID, model.StatusCompleted); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without a status the task is left alone.
+	ref := "flow-" + strconv.Itoa(task.ID)
+	res, err := Merge(s, []Node{{ID: ref, Title: "Done"}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Unchanged != 1 {
+		t.Errorf("merge without a status = %+v, want it unchanged", res)
+	}
+
+	nodes, err := ParseYAML("tasks:\n  - id: " + ref + "\n    title: Done\n    status: not_started\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err = Merge(s, nodes, nil); err != nil {
+		t.Fatal(err)
+	}
+	got, err := s.GetByID(task.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Updated != 1 || got.Status != model.StatusNotStarted {
+		t.Errorf("merge with status not_started = %+v, status %v; want the task reopened", res, got.Status)
+	}
+}
+
+func TestMergeCountsTagColors(t *testing.T) {
+	s := newTestStore(t)
+	task, err := s.Add("Report", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tag, err := s.EnsureTag("work", "39")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AssignTag(task.ID, tag.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		color       string
+		wantUpdated int
+	}{
+		{"39", 0},
+		{"205", 1},
+	} {
+		n := Node{Title: "Report", Tags: []string{"work"}, TagColors: map[string]string{"work": tc.color}}
+		res, err := Merge(s, []Node{n}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Updated != tc.wantUpdated || res.Updated+res.Unchanged != 1 {
+			t.Errorf("merge with color %s = %+v, want %d updated", tc.color, res, tc.wantUpdated)
+		}
+	}
+	got, err := s.TagByID(tag.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Color != "205" {
+		t.Errorf("tag color = %s, want 205", got.Color)
+	}
+}
@@ -0,0 +1,273 @@
+package importer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// ImportPlan is a parsed import checked against the store without writing
+// anything. Items can be deselected before the plan is carried out with
+// Nodes and Create.
+type ImportPlan struct {
+	Items []PlanItem
+	known map[string]bool // tag names that already exist
+}
+
+// PlanItem is one task of an ImportPlan, listed depth-first.
+type PlanItem struct {
+	Node     Node // without Children; see ImportPlan.Nodes
+	Depth    int
+	Parent   int // index of the parent item, -1 for roots
+	Problems []string
+	Selected bool
+}
+
+// Plan flattens nodes into an ImportPlan with every item selected and
+// records the problems that would make the import fail, as checked by
+// Validate.
+func Plan(s *store.TaskStore, nodes []Node) (*ImportPlan, error) {
+	tags, err := s.ListTags()
+	if err != nil {
+		return nil, err
+	}
+	p := &ImportPlan{known: make(map[string]bool, len(tags))}
+	for _, t := range tags {
+		p.known[t.Name] = true
+	}
+	var add func(nodes []Node, depth, parent int)
+	add = func(nodes []Node, depth, parent int) {
+		for _, n := range nodes {
+			item := PlanItem{Node: n, Depth: depth, Parent: parent, Selected: true}
+			item.Node.Children = nil
+			p.Items = append(p.Items, item)
+			add(n.Children, depth+1, len(p.Items)-1)
+		}
+	}
+	add(nodes, 0, -1)
+	for i, problems := range listProblems(flatten(nodes)) {
+		p.Items[i].Problems = problems
+	}
+	return p, nil
+}
+
+// flatten lists nodes and their descendants depth-first.
+func flatten(nodes []Node) []Node {
+	var flat []Node
+	for _, n := range nodes {
+		flat = append(flat, n)
+		flat = append(flat, flatten(n.Children)...)
+	}
+	return flat
+}
+
+// listProblems returns the problems of each node in flat, including ids
+// used twice and depends_on links to unknown ids or forming a cycle.
+func listProblems(flat []Node) [][]string {
+	problems := make([][]string, len(flat))
+	byID := make(map[string]int)
+	for i, n := range flat {
+		problems[i] = nodeProblems(n)
+		if n.ID == "" {
+			continue
+		}
+		if _, dup := byID[n.ID]; dup {
+			problems[i] = append(problems[i], fmt.Sprintf("duplicate id %q", n.ID))
+			continue
+		}
+		byID[n.ID] = i
+	}
+	for i, n := range flat {
+		if len(n.DependsOn) > 0 && n.ID == "" {
+			problems[i] = append(problems[i], "depends_on requires an id")
+		}
+		for _, dep := range n.DependsOn {
+			if _, ok := byID[dep]; !ok {
+				problems[i] = append(problems[i], fmt.Sprintf("depends_on: no task with id %q in this import", dep))
+			}
+		}
+	}
+
+	// Depth-first search for cycles: 1 while on the stack, 2 when done.
+	state := make(map[int]int)
+	var visit func(i int) bool
+	visit = func(i int) bool {
+		switch state[i] {
+		case 1:
+			return true
+		case 2:
+			return false
+		}
+		state[i] = 1
+		for _, dep := range flat[i].DependsOn {
+			if j, ok := byID[dep]; ok && visit(j) {
+				state[i] = 2
+				problems[i] = append(problems[i], "depends_on forms a cycle")
+				return true
+			}
+		}
+		state[i] = 2
+		return false
+	}
+	for i := range flat {
+		visit(i)
+	}
+	return problems
+}
+
+func nodeProblems(n Node) []string {
+	var problems []string
+	if strings.TrimSpace(n.Title) == "" {
+		problems = append(problems, "task title is required")
+	}
+	for _, d := range []struct{ name, value string }{{"due", n.DueDate}, {"scheduled", n.ScheduledOn}} {
+		if d.value == "" {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", d.value); err != nil {
+			problems = append(problems, fmt.Sprintf("invalid %s date %q (want YYYY-MM-DD)", d.name, d.value))
+		}
+	}
+	for _, name := range n.Tags {
+		for _, seg := range strings.Split(name, model.TagSeparator) {
+			if strings.TrimSpace(seg) == "" {
+				problems = append(problems, fmt.Sprintf("invalid tag name %q", name))
+				break
+			}
+		}
+	}
+	for name, color := range n.TagColors {
+		if !validColor(color) {
+			problems = append(problems, fmt.Sprintf("invalid color %q for tag %q (want 0-255 or #rrggbb)", color, name))
+		}
+	}
+	return problems
+}
+
+// validColor reports whether c is a terminal color code, 0 to 255, or a
+// hex color such as #ff8800.
+func validColor(c string) bool {
+	if n, err := strconv.Atoi(c); err == nil {
+		return n >= 0 && n <= 255
+	}
+	if len(c) != 4 && len(c) != 7 || c[0] != '#' {
+		return false
+	}
+	_, err := strconv.ParseUint(c[1:], 16, 32)
+	return err == nil
+}
+
+func problemText(title, msg string) string {
+	if strings.TrimSpace(title) == "" {
+		return msg
+	}
+	return fmt.Sprintf("%q: %s", title, msg)
+}
+
+// Included reports whether item i and all its ancestors are selected.
+func (p *ImportPlan) Included(i int) bool {
+	for ; i >= 0; i = p.Items[i].Parent {
+		if !p.Items[i].Selected {
+			return false
+		}
+	}
+	return true
+}
+
+// Toggle selects or deselects item i; a deselected item excludes its whole
+// branch.
+func (p *ImportPlan) Toggle(i int) {
+	p.Items[i].Selected = !p.Items[i].Selected
+}
+
+// Count returns the number of included tasks.
+func (p *ImportPlan) Count() int {
+	n := 0
+	for i := range p.Items {
+		if p.Included(i) {
+			n++
+		}
+	}
+	return n
+}
+
+// Problems returns the problems of the included tasks, prefixed with their
+// titles. Unlike the Problems of each item, these take deselected tasks
+// into account, e.g. a dependency on a task that is left out.
+func (p *ImportPlan) Problems() []string {
+	var flat []Node
+	for i, it := range p.Items {
+		if p.Included(i) {
+			flat = append(flat, it.Node)
+		}
+	}
+	var problems []string
+	for i, msgs := range listProblems(flat) {
+		for _, msg := range msgs {
+			problems = append(problems, problemText(flat[i].Title, msg))
+		}
+	}
+	return problems
+}
+
+// IsNewTag reports whether importing would create the tag name.
+func (p *ImportPlan) IsNewTag(name string) bool {
+	return !p.known[name]
+}
+
+// NewTags returns the tags the included tasks would create, parents before
+// their nested tags.
+func (p *ImportPlan) NewTags() []string {
+	seen := make(map[string]bool)
+	var tags []string
+	for i, it := range p.Items {
+		if !p.Included(i) {
+			continue
+		}
+		for _, name := range it.Node.Tags {
+			var missing []string
+			for t := name; t != "" && !p.known[t] && !seen[t]; t = model.ParentTagName(t) {
+				seen[t] = true
+				missing = append([]string{t}, missing...)
+			}
+			tags = append(tags, missing...)
+		}
+	}
+	return tags
+}
+
+// Nodes rebuilds the tree of the included tasks.
+func (p *ImportPlan) Nodes() []Node {
+	var build func(parent int) []Node
+	build = func(parent int) []Node {
+		var nodes []Node
+		for i, it := range p.Items {
+			if it.Parent != parent || !it.Selected {
+				continue
+			}
+			n := it.Node
+			n.Children = build(i)
+			nodes = append(nodes, n)
+		}
+		return nodes
+	}
+	return build(-1)
+}
+
+// IsLast reports whether item i is the last child of its parent, for
+// drawing tree branches.
+func (p *ImportPlan) IsLast(i int) bool {
+	for _, it := range p.Items[i+1:] {
+		if it.Parent == p.Items[i].Parent {
+			return false
+		}
+		if it.Depth < p.Items[i].Depth {
+			break
+		}
+	}
+	return true
+}
@@ -0,0 +1,229 @@
+package importer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// Subtree returns the task with the given ID and all its descendants, in
+// the order of all.
+func Subtree(all []model.Task, rootID int) []model.Task {
+	in := map[int]bool{rootID: true}
+	// Parents may appear after their children in all, so repeat until no
+	// more descendants are found.
+	for changed := true; changed; {
+		changed = false
+		for _, t := range all {
+			if !in[t.ID] && t.ParentID != nil && in[*t.ParentID] {
+				in[t.ID], changed = true, true
+			}
+		}
+	}
+	var out []model.Task
+	for _, t := range all {
+		if in[t.ID] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// walkTasks calls fn for tasks depth-first, parents before children, with
+// the nesting depth of each task within tasks (0 for roots).
+func walkTasks(tasks []model.Task, fn func(t model.Task, depth int)) {
+	depth := make(map[int]int, len(tasks))
+	for _, t := range parentsFirst(tasks) {
+		d := 0
+		if t.ParentID != nil {
+			if pd, ok := depth[*t.ParentID]; ok {
+				d = pd + 1
+			}
+		}
+		depth[t.ID] = d
+		fn(t, d)
+	}
+}
+
+// WriteOrg writes tasks as an Org-mode outline: one headline per task with
+// a TODO, STARTED or DONE keyword, [#A] priority cookie and :tags:,
+// followed by DEADLINE/SCHEDULED, a property drawer and the description.
+func WriteOrg(w io.Writer, tasks []model.Task, title string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "#+TITLE: %s\n", title)
+	fmt.Fprintln(bw, "#+TODO: TODO STARTED | DONE")
+	walkTasks(tasks, func(t model.Task, depth int) {
+		indent := strings.Repeat(" ", depth+2)
+
+		fmt.Fprintf(bw, "\n%s %s ", strings.Repeat("*", depth+1), orgKeyword(t.Status))
+		if p := t.PriorityLetter(); p != "" {
+			fmt.Fprintf(bw, "[#%s] ", p)
+		}
+		bw.WriteString(strings.Join(strings.Fields(t.Title), " "))
+		if len(t.Tags) > 0 {
+			names := make([]string, len(t.Tags))
+			for i, tag := range t.Tags {
+				names[i] = orgTag(tag.Name)
+			}
+			fmt.Fprintf(bw, " :%s:", strings.Join(names, ":"))
+		}
+		bw.WriteString("\n")
+
+		var planning []string
+		if t.DueDate != nil {
+			planning = append(planning, "DEADLINE: "+orgDate(*t.DueDate))
+		}
+		if t.ScheduledOn != nil {
+			planning = append(planning, "SCHEDULED: "+orgDate(*t.ScheduledOn))
+		}
+		if len(planning) > 0 {
+			fmt.Fprintf(bw, "%s%s\n", indent, strings.Join(planning, " "))
+		}
+
+		fmt.Fprintf(bw, "%s:PROPERTIES:\n", indent)
+		fmt.Fprintf(bw, "%s:ID:       flow-%d\n", indent, t.ID)
+		fmt.Fprintf(bw, "%s:CREATED:  %s\n", indent, t.CreatedAt.Local().Format("[2006-01-02 Mon 15:04]"))
+		fmt.Fprintf(bw, "%s:END:\n", indent)
+
+		if t.Description != nil && strings.TrimSpace(*t.Description) != "" {
+			// Indented body lines can never be read as headlines.
+			for _, line := range strings.Split(strings.TrimRight(*t.Description, "\n"), "\n") {
+				fmt.Fprintf(bw, "%s%s\n", indent, line)
+			}
+		}
+	})
+	return bw.Flush()
+}
+
+func orgKeyword(s model.TaskStatus) string {
+	switch s {
+	case model.StatusInProgress:
+		return "STARTED"
+	case model.StatusCompleted:
+		return "DONE"
+	}
+	return "TODO"
+}
+
+// orgDate formats YYYY-MM-DD as an active Org timestamp, <2026-11-01 Sun>.
+func orgDate(d string) string {
+	t, err := time.Parse("2006-01-02", d)
+	if err != nil {
+		return "<" + d + ">"
+	}
+	return t.Format("<2006-01-02 Mon>")
+}
+
+// orgTag replaces characters Org does not allow in tags, including the
+// "/" of nested tag names.
+func orgTag(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r == '_' || r == '@' || r == '#' || r == '%':
+			return r
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r > 127:
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// WriteMarkdownReport writes tasks as a Markdown document: a heading per
+// root task and nested checklist items below it, each with status, due and
+// scheduled badges, tags and the description as body text.
+func WriteMarkdownReport(w io.Writer, tasks []model.Task, title string) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", title)
+	fmt.Fprintf(bw, "_Exported %s · %d tasks_\n", time.Now().Format("2006-01-02 15:04"), len(tasks))
+	walkTasks(tasks, func(t model.Task, depth int) {
+		meta := markdownBadges(t)
+		desc := ""
+		if t.Description != nil {
+			desc = strings.TrimSpace(*t.Description)
+		}
+
+		if depth == 0 {
+			fmt.Fprintf(bw, "\n## %s\n\n", markdownTitle(t))
+			if meta != "" {
+				fmt.Fprintf(bw, "%s\n\n", meta)
+			}
+			if desc != "" {
+				fmt.Fprintf(bw, "%s\n\n", desc)
+			}
+			return
+		}
+
+		indent := strings.Repeat("  ", depth-1)
+		item := fmt.Sprintf("%s- %s %s", indent, statusBox(t.Status), markdownTitle(t))
+		if meta != "" {
+			item += " — " + meta
+		}
+		fmt.Fprintln(bw, item)
+		if desc != "" {
+			// Blank-line-separated paragraphs indented under the item.
+			fmt.Fprintln(bw)
+			for _, line := range strings.Split(desc, "\n") {
+				if strings.TrimSpace(line) == "" {
+					fmt.Fprintln(bw)
+					continue
+				}
+				fmt.Fprintf(bw, "%s  %s\n", indent, line)
+			}
+			fmt.Fprintln(bw)
+		}
+	})
+	return bw.Flush()
+}
+
+func markdownTitle(t model.Task) string {
+	title := t.Title
+	if p := t.PriorityLetter(); p != "" {
+		title = "(" + p + ") " + title
+	}
+	if t.Status == model.StatusCompleted {
+		title = "~~" + title + "~~"
+	}
+	return title
+}
+
+func statusBox(s model.TaskStatus) string {
+	switch s {
+	case model.StatusInProgress:
+		return "[-]"
+	case model.StatusCompleted:
+		return "[x]"
+	}
+	return "[ ]"
+}
+
+// markdownBadges renders the status, dates and tags of a task.
+func markdownBadges(t model.Task) string {
+	var badges []string
+	switch t.Status {
+	case model.StatusInProgress:
+		badges = append(badges, "🔄 in progress")
+	case model.StatusCompleted:
+		badges = append(badges, "✅ done")
+	}
+	if t.DueDate != nil {
+		switch {
+		case t.IsOverdue():
+			badges = append(badges, "⚠️ **overdue "+*t.DueDate+"**")
+		case t.IsDueToday():
+			badges = append(badges, "📅 **due today**")
+		default:
+			badges = append(badges, "📅 due "+*t.DueDate)
+		}
+	}
+	if t.ScheduledOn != nil {
+		badges = append(badges, "📌 "+*t.ScheduledOn)
+	}
+	for _, tag := range t.Tags {
+		badges = append(badges, "`#"+tag.Name+"`")
+	}
+	return strings.Join(badges, " · ")
+}
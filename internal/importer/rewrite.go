@@ -0,0 +1,336 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// ChangeKind is the kind of a Change.
+type ChangeKind int
+
+const (
+	ChangeCreate ChangeKind = iota
+	ChangeRename
+	ChangeMove
+	ChangeRetag
+	ChangeRedate
+	ChangeDelete
+)
+
+// Change is a single edit found by DiffSubtree. Changes are accepted or
+// rejected one by one before they are applied.
+type Change struct {
+	Kind   ChangeKind
+	TaskID int    // the changed task, 0 for ChangeCreate
+	Title  string // the current title, or the title of a new task
+
+	// ParentID is the parent of a created or moved task, nil for a
+	// top-level task, unless ParentChange is the index of the ChangeCreate
+	// of a new parent; it is -1 otherwise. OldParent and NewParent are the
+	// parent titles, "" for the top level.
+	ParentID     *int
+	ParentChange int
+	OldParent    string
+	NewParent    string
+
+	Node     Node   // ChangeCreate: the new task, without children
+	NewTitle string // ChangeRename
+
+	OldTags, NewTags []string          // ChangeRetag, sorted
+	TagColors        map[string]string // ChangeRetag: colors for NewTags
+
+	// ChangeRedate: the dates as YYYY-MM-DD, "" for none.
+	OldDueDate, NewDueDate         string
+	OldScheduledOn, NewScheduledOn string
+}
+
+// Rewrite is the difference between the subtree of a task and a
+// rewritten copy of it.
+type Rewrite struct {
+	RootID  int
+	Changes []Change
+}
+
+// DiffSubtree compares the task rootID and its descendants with nodes, a
+// rewritten copy of the subtree as returned by an AI assistant. nodes is
+// either the root task itself, with its ID, or the new list of its
+// children. Nodes with the ID of a task in the subtree stand for that task:
+// a different title is a rename, a different parent a move, and different
+// tags, due or scheduled dates are replaced. Other nodes are new tasks, and
+// tasks of the subtree that no node stands for are deleted. Other fields of
+// existing tasks, and depends_on, are ignored.
+func DiffSubtree(s *store.TaskStore, rootID int, nodes []Node) (*Rewrite, error) {
+	if err := Validate(nodes); err != nil {
+		return nil, err
+	}
+	all, err := s.List()
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int]model.Task, len(all))
+	children := make(map[int][]model.Task)
+	for _, t := range all {
+		byID[t.ID] = t
+		if t.ParentID != nil {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+	root, ok := byID[rootID]
+	if !ok {
+		return nil, fmt.Errorf("task %d not found", rootID)
+	}
+	inSubtree := make(map[int]bool)
+	var mark func(id int)
+	mark = func(id int) {
+		inSubtree[id] = true
+		for _, c := range children[id] {
+			mark(c.ID)
+		}
+	}
+	mark(rootID)
+
+	d := &differ{r: &Rewrite{RootID: rootID}, byID: byID, inSubtree: inSubtree, seen: map[int]bool{rootID: true}}
+	if len(nodes) == 1 && nodeTaskID(nodes[0]) == rootID {
+		d.compare(root, nodes[0])
+		nodes = nodes[0].Children
+	}
+	if err := d.walk(nodes, &rootID, -1, root.Title); err != nil {
+		return nil, err
+	}
+
+	// Deepest first, so that a task is deleted after its sub-tasks.
+	var deletes func(id int)
+	deletes = func(id int) {
+		for _, c := range children[id] {
+			deletes(c.ID)
+			if !d.seen[c.ID] {
+				d.r.Changes = append(d.r.Changes, Change{Kind: ChangeDelete, TaskID: c.ID, Title: c.Title, ParentChange: -1})
+			}
+		}
+	}
+	deletes(rootID)
+	return d.r, nil
+}
+
+type differ struct {
+	r         *Rewrite
+	byID      map[int]model.Task
+	inSubtree map[int]bool
+	seen      map[int]bool
+}
+
+// walk compares nodes with the tasks they stand for under a parent that is
+// either the existing task parentID or the new task created by change
+// parentChange.
+func (d *differ) walk(nodes []Node, parentID *int, parentChange int, parentTitle string) error {
+	for _, n := range nodes {
+		id := nodeTaskID(n)
+		if id == 0 {
+			d.r.Changes = append(d.r.Changes, Change{
+				Kind:         ChangeCreate,
+				Title:        n.Title,
+				ParentID:     parentID,
+				ParentChange: parentChange,
+				NewParent:    parentTitle,
+				Node:         withoutChildren(n),
+			})
+			if err := d.walk(n.Children, nil, len(d.r.Changes)-1, n.Title); err != nil {
+				return err
+			}
+			continue
+		}
+
+		t, ok := d.byID[id]
+		if !ok || !d.inSubtree[id] {
+			return fmt.Errorf("task %q: id %s is not in the subtree of task %d", n.Title, n.ID, d.r.RootID)
+		}
+		if d.seen[id] {
+			return fmt.Errorf("task %q: task %d appears more than once", n.Title, id)
+		}
+		d.seen[id] = true
+		if parentChange >= 0 || t.ParentID == nil || *t.ParentID != *parentID {
+			old := ""
+			if t.ParentID != nil {
+				old = d.byID[*t.ParentID].Title
+			}
+			d.r.Changes = append(d.r.Changes, Change{
+				Kind:         ChangeMove,
+				TaskID:       id,
+				Title:        t.Title,
+				ParentID:     parentID,
+				ParentChange: parentChange,
+				OldParent:    old,
+				NewParent:    parentTitle,
+			})
+		}
+		d.compare(t, n)
+		if err := d.walk(n.Children, &id, -1, n.Title); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// compare adds the renames, retags and redates that turn t into n.
+func (d *differ) compare(t model.Task, n Node) {
+	base := Change{TaskID: t.ID, Title: t.Title, ParentChange: -1}
+	if title := strings.TrimSpace(n.Title); title != t.Title {
+		c := base
+		c.Kind, c.NewTitle = ChangeRename, title
+		d.r.Changes = append(d.r.Changes, c)
+	}
+
+	var oldTags []string
+	for _, tag := range t.Tags {
+		oldTags = append(oldTags, tag.Name)
+	}
+	slices.Sort(oldTags)
+	newTags := slices.Clone(n.Tags)
+	slices.Sort(newTags)
+	newTags = slices.Compact(newTags)
+	if !slices.Equal(oldTags, newTags) {
+		c := base
+		c.Kind, c.OldTags, c.NewTags, c.TagColors = ChangeRetag, oldTags, newTags, n.TagColors
+		d.r.Changes = append(d.r.Changes, c)
+	}
+
+	if deref(t.DueDate) != n.DueDate || deref(t.ScheduledOn) != n.ScheduledOn {
+		c := base
+		c.Kind = ChangeRedate
+		c.OldDueDate, c.NewDueDate = deref(t.DueDate), n.DueDate
+		c.OldScheduledOn, c.NewScheduledOn = deref(t.ScheduledOn), n.ScheduledOn
+		d.r.Changes = append(d.r.Changes, c)
+	}
+}
+
+// nodeTaskID returns the task ID a node refers to, such as 12 for
+// "flow-12", or 0. Other IDs, plain numbers included, only name a task
+// within the document.
+func nodeTaskID(n Node) int {
+	s, ok := strings.CutPrefix(n.ID, "flow-")
+	if !ok {
+		return 0
+	}
+	id, err := strconv.Atoi(s)
+	if err != nil || id <= 0 {
+		return 0
+	}
+	return id
+}
+
+func withoutChildren(n Node) Node {
+	n.Children = nil
+	n.DependsOn = nil
+	return n
+}
+
+// RewriteResult counts the changes made by Apply.
+type RewriteResult struct {
+	Applied int
+	// Skipped counts accepted changes that depend on a rejected one: tasks
+	// to create or move under a rejected new task, moves that would make a
+	// task its own ancestor, and deletions of tasks that still have
+	// sub-tasks.
+	Skipped int
+}
+
+// Apply makes the changes for which accepted is true in a single
+// transaction.
+func (r *Rewrite) Apply(s *store.TaskStore, accepted []bool) (RewriteResult, error) {
+	var res RewriteResult
+	err := s.WithTx(func(tx *store.TaskStore) error {
+		res = RewriteResult{}
+		created := make(map[int]int) // change index to new task ID
+		for i, c := range r.Changes {
+			if !accepted[i] {
+				continue
+			}
+			parentID := c.ParentID
+			if c.ParentChange >= 0 {
+				id, ok := created[c.ParentChange]
+				if !ok {
+					res.Skipped++
+					continue
+				}
+				parentID = &id
+			}
+
+			id, ok, err := applyChange(tx, c, parentID)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				res.Skipped++
+				continue
+			}
+			if c.Kind == ChangeCreate {
+				created[i] = id
+			}
+			res.Applied++
+		}
+		return nil
+	})
+	if err != nil {
+		return RewriteResult{}, err
+	}
+	return res, nil
+}
+
+// applyChange makes c, placing created and moved tasks under parentID. It
+// returns the ID of a created task, and false if c had to be skipped.
+func applyChange(s *store.TaskStore, c Change, parentID *int) (int, bool, error) {
+	switch c.Kind {
+	case ChangeCreate:
+		t, err := createTask(s, c.Node, parentID)
+		return t.ID, err == nil, err
+	case ChangeMove:
+		err := s.SetParent(c.TaskID, parentID)
+		if errors.Is(err, store.ErrParentCycle) {
+			return 0, false, nil
+		}
+		return 0, err == nil, err
+	case ChangeRename:
+		_, err := s.UpdateTask(c.TaskID, 0, store.TaskPatch{Title: &c.NewTitle})
+		return 0, err == nil, err
+	case ChangeRetag:
+		t, err := s.GetByID(c.TaskID)
+		if err != nil {
+			return 0, false, err
+		}
+		for _, tag := range t.Tags {
+			if !slices.Contains(c.NewTags, tag.Name) {
+				if err := s.UnassignTag(t.ID, tag.ID); err != nil {
+					return 0, false, err
+				}
+			}
+		}
+		if err := assignTags(s, t.ID, c.NewTags, c.TagColors); err != nil {
+			return 0, false, fmt.Errorf("assign tags for %q: %w", t.Title, err)
+		}
+		return 0, true, nil
+	case ChangeRedate:
+		p := store.TaskPatch{ClearDueDate: c.NewDueDate == "", ClearScheduledOn: c.NewScheduledOn == ""}
+		if c.NewDueDate != "" {
+			p.DueDate = &c.NewDueDate
+		}
+		if c.NewScheduledOn != "" {
+			p.ScheduledOn = &c.NewScheduledOn
+		}
+		_, err := s.UpdateTask(c.TaskID, 0, p)
+		return 0, err == nil, err
+	case ChangeDelete:
+		// Sub-tasks whose move was rejected would go with it.
+		has, err := s.HasChildren(c.TaskID)
+		if err != nil || has {
+			return 0, false, err
+		}
+		return 0, true, s.Delete(c.TaskID)
+	}
+	return 0, false, fmt.Errorf("unknown change kind %d", c.Kind)
+}
@@ -0,0 +1,196 @@
+package importer
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// newRewriteStore returns a store holding
+//
+//	R (flow-1)
+//	  A (flow-2)
+//	    A1 (flow-3)
+//	  B (flow-4)
+//	X (flow-5)
+func newRewriteStore(t *testing.T) *store.TaskStore {
+	t.Helper()
+	s := newTestStore(t)
+	for _, tc := range []struct {
+		title  string
+		parent int
+	}{{"R", 0}, {"A", 1}, {"A1", 2}, {"B", 1}, {"X", 0}} {
+		var parentID *int
+		if tc.parent != 0 {
+			parentID = &tc.parent
+		}
+		if _, err := s.Add(tc.title, parentID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return s
+}
+
+// outline renders the tasks of s as "R[A[A1] B] X", sub-tasks in ID order.
+func outline(t *testing.T, s *store.TaskStore) string {
+	t.Helper()
+	all, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.SortFunc(all, func(a, b model.Task) int { return a.ID - b.ID })
+	var render func(parent *int) string
+	render = func(parent *int) string {
+		var parts []string
+		for _, task := range all {
+			if (parent == nil) != (task.ParentID == nil) || parent != nil && *parent != *task.ParentID {
+				continue
+			}
+			id := task.ID
+			if sub := render(&id); sub != "" {
+				parts = append(parts, task.Title+"["+sub+"]")
+			} else {
+				parts = append(parts, task.Title)
+			}
+		}
+		return strings.Join(parts, " ")
+	}
+	return render(nil)
+}
+
+var changeKindNames = []string{"create", "rename", "move", "retag", "redate", "delete"}
+
+func TestRewrite(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		yaml     string
+		reject   []int // indices of rejected changes
+		changes  string
+		applied  int
+		skipped  int
+		wantTree string
+	}{
+		{
+			name: "rename, move and delete",
+			yaml: `
+- id: flow-1
+  title: R
+  children:
+    - id: flow-4
+      title: Beta
+      children:
+        - id: flow-3
+          title: A1
+        - title: New
+`,
+			changes:  "rename move create delete",
+			applied:  4,
+			wantTree: "R[Beta[A1 New]] X",
+		},
+		{
+			name: "under a rejected new parent",
+			yaml: `
+- id: flow-2
+  title: A
+  children:
+    - id: flow-3
+      title: A1
+- title: Group
+  children:
+    - id: flow-4
+      title: B
+    - title: Child
+`,
+			reject:   []int{0},
+			changes:  "create move create",
+			skipped:  2,
+			wantTree: "R[A[A1] B] X",
+		},
+		{
+			name: "cyclic move",
+			yaml: `
+- id: flow-3
+  title: A1
+  children:
+    - id: flow-2
+      title: A
+- id: flow-4
+  title: B
+`,
+			reject:   []int{0},
+			changes:  "move move",
+			skipped:  1,
+			wantTree: "R[A[A1] B] X",
+		},
+		{
+			name: "delete with a rejected move out",
+			yaml: `
+- id: flow-4
+  title: B
+  children:
+    - id: flow-3
+      title: A1
+`,
+			reject:   []int{0},
+			changes:  "move delete",
+			skipped:  1,
+			wantTree: "R[A[A1] B] X",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newRewriteStore(t)
+			nodes, err := ParseYAML("tasks:\n" + indentLines(strings.TrimPrefix(tc.yaml, "\n")))
+			if err != nil {
+				t.Fatal(err)
+			}
+			r, err := DiffSubtree(s, 1, nodes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var kinds []string
+			accepted := make([]bool, len(r.Changes))
+			for i, c := range r.Changes {
+				kinds = append(kinds, changeKindNames[c.Kind])
+				accepted[i] = !slices.Contains(tc.reject, i)
+			}
+			if got := strings.Join(kinds, " "); got != tc.changes {
+				t.Fatalf("changes = %s, want %s", got, tc.changes)
+			}
+			res, err := r.Apply(s, accepted)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if res.Applied != tc.applied || res.Skipped != tc.skipped {
+				t.Errorf("Apply = %+v, want %d applied and %d skipped", res, tc.applied, tc.skipped)
+			}
+			if got := outline(t, s); got != tc.wantTree {
+				t.Errorf("tasks = %s, want %s", got, tc.wantTree)
+			}
+		})
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	for _, tc := range []struct{ name, yaml, want string }{
+		{"outside the subtree", "- id: flow-5\n  title: X\n", "not in the subtree"},
+		{"duplicate id", "- id: flow-2\n  title: A\n- id: flow-4\n  title: B\n  children:\n    - id: flow-2\n      title: A\n", `duplicate id "flow-2"`},
+		{"repeated task", "- id: flow-2\n  title: A\n- id: flow-4\n  title: B\n  children:\n    - id: flow-02\n      title: A\n", "appears more than once"},
+	} {
+		s := newRewriteStore(t)
+		nodes, err := ParseYAML("tasks:\n" + indentLines(tc.yaml))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := DiffSubtree(s, 1, nodes); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("%s: DiffSubtree = %v, want an error mentioning %q", tc.name, err, tc.want)
+		}
+	}
+}
+
+// indentLines indents the lines of s by two spaces, to go below "tasks:".
+func indentLines(s string) string {
+	return "  " + strings.ReplaceAll(strings.TrimSuffix(s, "\n"), "\n", "\n  ") + "\n"
+}
@@ -0,0 +1,185 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// twTask is an entry of `task export` output.
+type twTask struct {
+	UUID        string         `json:"uuid"`
+	Description string         `json:"description"`
+	Status      string         `json:"status"`
+	Start       string         `json:"start"`
+	Due         string         `json:"due"`
+	Scheduled   string         `json:"scheduled"`
+	Priority    string         `json:"priority"`
+	Project     string         `json:"project"`
+	Tags        []string       `json:"tags"`
+	Annotations []twAnnotation `json:"annotations"`
+	Depends     twDepends      `json:"depends"`
+}
+
+type twAnnotation struct {
+	Description string `json:"description"`
+}
+
+// twDepends accepts both the array form of Taskwarrior 2.6+ and the
+// comma-separated string of earlier versions.
+type twDepends []string
+
+func (d *twDepends) UnmarshalJSON(data []byte) error {
+	var list []string
+	if err := json.Unmarshal(data, &list); err == nil {
+		*d = list
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	for _, u := range strings.Split(s, ",") {
+		if u = strings.TrimSpace(u); u != "" {
+			*d = append(*d, u)
+		}
+	}
+	return nil
+}
+
+// twPriorities maps Taskwarrior priorities to flow priorities.
+var twPriorities = map[string]int{"H": 1, "M": 2, "L": 3}
+
+// isTaskwarrior reports whether text looks like `task export` output.
+func isTaskwarrior(text string) bool {
+	t := strings.TrimSpace(text)
+	return (strings.HasPrefix(t, "[") || strings.HasPrefix(t, "{")) &&
+		strings.Contains(t, `"uuid"`) && strings.Contains(t, `"description"`)
+}
+
+// ParseTaskwarrior reads the JSON written by `task export`, either an array
+// or one object per line. The description becomes the title, annotations
+// the description and the project a nested tag ("home.garden" becomes
+// "home/garden"). Started tasks are in progress; deleted tasks and
+// recurrence templates are skipped. The uuid becomes the node ID and depends
+// its depends_on links; links to tasks that are not imported are dropped.
+func ParseTaskwarrior(text string) ([]Node, error) {
+	var tasks []twTask
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "[") {
+		if err := json.Unmarshal([]byte(text), &tasks); err != nil {
+			return nil, fmt.Errorf("Taskwarrior JSON parse error: %w", err)
+		}
+	} else {
+		dec := json.NewDecoder(strings.NewReader(text))
+		for dec.More() {
+			var t twTask
+			if err := dec.Decode(&t); err != nil {
+				return nil, fmt.Errorf("Taskwarrior JSON parse error: %w", err)
+			}
+			tasks = append(tasks, t)
+		}
+	}
+
+	var nodes []Node
+	var depends [][]string
+	byUUID := make(map[string]int)
+	for _, t := range tasks {
+		if t.Status == "deleted" || t.Status == "recurring" {
+			continue
+		}
+		n, err := twNode(t)
+		if err != nil {
+			return nil, fmt.Errorf("task %s: %w", t.UUID, err)
+		}
+		if t.UUID != "" {
+			byUUID[t.UUID] = len(nodes)
+		}
+		nodes = append(nodes, n)
+		depends = append(depends, t.Depends)
+	}
+	if len(nodes) == 0 {
+		return nil, fmt.Errorf("no tasks found in Taskwarrior export")
+	}
+
+	// Links to tasks that are not imported are dropped, and so is a link
+	// that would close a cycle.
+	var reaches func(from, to int) bool
+	reaches = func(from, to int) bool {
+		if from == to {
+			return true
+		}
+		for _, dep := range nodes[from].DependsOn {
+			if reaches(byUUID[dep], to) {
+				return true
+			}
+		}
+		return false
+	}
+	for i := range nodes {
+		for _, dep := range depends[i] {
+			j, ok := byUUID[dep]
+			if !ok || reaches(j, i) {
+				continue
+			}
+			nodes[i].DependsOn = append(nodes[i].DependsOn, dep)
+		}
+	}
+	return nodes, nil
+}
+
+func twNode(t twTask) (Node, error) {
+	n := Node{
+		ID:        t.UUID,
+		Title:     strings.TrimSpace(t.Description),
+		StatusSet: true,
+		Priority:  twPriorities[t.Priority],
+		Tags:      append([]string{}, t.Tags...),
+	}
+	if n.Title == "" {
+		return Node{}, fmt.Errorf("task title is required")
+	}
+	switch {
+	case t.Status == "completed":
+		n.Status = model.StatusCompleted
+	case t.Start != "":
+		n.Status = model.StatusInProgress
+	}
+
+	var notes []string
+	for _, a := range t.Annotations {
+		if d := strings.TrimSpace(a.Description); d != "" {
+			notes = append(notes, d)
+		}
+	}
+	n.Description = strings.Join(notes, "\n")
+
+	if t.Project != "" {
+		n.Tags = append(n.Tags, strings.ReplaceAll(t.Project, ".", model.TagSeparator))
+	}
+
+	var err error
+	if n.DueDate, err = twDate(t.Due); err != nil {
+		return Node{}, fmt.Errorf("due: %w", err)
+	}
+	if n.ScheduledOn, err = twDate(t.Scheduled); err != nil {
+		return Node{}, fmt.Errorf("scheduled: %w", err)
+	}
+	return n, nil
+}
+
+// twDate converts a Taskwarrior UTC timestamp such as 20261101T000000Z to a
+// local calendar date.
+func twDate(v string) (string, error) {
+	if v == "" {
+		return "", nil
+	}
+	t, err := time.Parse("20060102T150405Z", v)
+	if err != nil {
+		return "", fmt.Errorf("invalid date %q", v)
+	}
+	return t.Local().Format("2006-01-02"), nil
+}
@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestTaskwarriorDepends(t *testing.T) {
+	// b depends on a, c on a and b, and d on a deleted task; e and f depend
+	// on each other.
+	text := `[
+{"uuid":"a","description":"A","status":"pending"},
+{"uuid":"b","description":"B","status":"pending","depends":["a"]},
+{"uuid":"c","description":"C","status":"pending","depends":"a,b"},
+{"uuid":"d","description":"D","status":"pending","depends":["x"]},
+{"uuid":"x","description":"X","status":"deleted"},
+{"uuid":"e","description":"E","status":"pending","depends":["f"]},
+{"uuid":"f","description":"F","status":"pending","depends":["e"]}
+]`
+	nodes, err := ParseTaskwarrior(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make(map[string][]string)
+	for _, n := range nodes {
+		if len(n.Children) > 0 {
+			t.Errorf("task %s has sub-tasks", n.ID)
+		}
+		got[n.Title] = n.DependsOn
+	}
+	want := map[string][]string{"A": nil, "B": {"a"}, "C": {"a", "b"}, "D": nil, "E": {"f"}, "F": nil}
+	for title, deps := range want {
+		if !slices.Equal(got[title], deps) {
+			t.Errorf("%s depends on %q, want %q", title, got[title], deps)
+		}
+	}
+
+	s := newTestStore(t)
+	if _, err := Create(s, nodes, nil); err != nil {
+		t.Fatal(err)
+	}
+	tasks, err := s.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ids := make(map[string]int)
+	for _, task := range tasks {
+		ids[task.Title] = task.ID
+	}
+	deps, err := s.Dependencies(ids["C"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(deps)
+	if !slices.Equal(deps, []int{ids["A"], ids["B"]}) {
+		t.Errorf("C depends on %v, want A and B %v", deps, []int{ids["A"], ids["B"]})
+	}
+}
@@ -0,0 +1,218 @@
+package importer
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// todo.txt lines start with an optional completion marker and dates, or a
+// priority and creation date: "x 2026-10-18 2026-10-01 ..." or "(A) ...".
+var (
+	todoDone     = regexp.MustCompile(`^x (?:\d{4}-\d{2}-\d{2} ){0,2}`)
+	todoPriority = regexp.MustCompile(`^\(([A-Z])\) (?:\d{4}-\d{2}-\d{2} )?`)
+	todoCreated  = regexp.MustCompile(`^\d{4}-\d{2}-\d{2} `)
+	todoToken    = regexp.MustCompile(`(?:^|\s)(?:[+@]\S|due:\d{4}-|t:\d{4}-)`)
+)
+
+// isTodoTxt reports whether text looks like a todo.txt file.
+func isTodoTxt(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		if todoDone.MatchString(line) || todoPriority.MatchString(line) || todoToken.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// todoEntry is a parsed todo.txt line and the project it belongs to.
+type todoEntry struct {
+	node    Node
+	project string
+}
+
+// ParseTodoTxt parses a todo.txt file. "x" marks completed tasks, "(A)" or
+// "pri:A" sets the priority, "@context" becomes a tag, "due:" the due date
+// and "t:" the scheduled date. A task's first "+project" makes it a child
+// of the line whose title matches the project name (spaces written as "-"),
+// or of a new task named after the project when there is no such line. A
+// backslash before such a word, as written by WriteTodoTxt, keeps it in the
+// title.
+func ParseTodoTxt(text string) ([]Node, error) {
+	var entries []todoEntry
+	for i, line := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		e, err := parseTodoLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		entries = append(entries, e)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no tasks found in todo.txt")
+	}
+
+	// Lines without a project are roots; a project refers to the first of
+	// them with a matching name, wherever it appears in the file.
+	var roots []*Node
+	byProject := make(map[string]*Node)
+	for i := range entries {
+		if entries[i].project != "" {
+			continue
+		}
+		n := &entries[i].node
+		roots = append(roots, n)
+		if name := projectName(n.Title); byProject[name] == nil {
+			byProject[name] = n
+		}
+	}
+	for _, e := range entries {
+		if e.project == "" {
+			continue
+		}
+		parent := byProject[e.project]
+		if parent == nil {
+			parent = &Node{Title: e.project}
+			roots = append(roots, parent)
+			byProject[e.project] = parent
+		}
+		parent.Children = append(parent.Children, e.node)
+	}
+
+	nodes := make([]Node, len(roots))
+	for i, r := range roots {
+		nodes[i] = *r
+	}
+	return nodes, nil
+}
+
+func parseTodoLine(line string) (todoEntry, error) {
+	var e todoEntry
+	rest := strings.TrimSpace(line) + " "
+	if m := todoDone.FindString(rest); m != "" {
+		e.node.Status = model.StatusCompleted
+		rest = rest[len(m):]
+	} else if m := todoPriority.FindStringSubmatch(rest); m != nil {
+		e.node.Priority, _ = model.ParsePriority(m[1])
+		rest = rest[len(m[0]):]
+	} else if m := todoCreated.FindString(rest); m != "" {
+		rest = rest[len(m):]
+	}
+
+	var words []string
+	for _, w := range strings.Fields(rest) {
+		key, value, hasValue := strings.Cut(w, ":")
+		switch {
+		case len(w) > 1 && w[0] == '\\' && (w[1] == '\\' || isTodoMarker(w[1:])):
+			words = append(words, w[1:])
+		case len(w) > 1 && w[0] == '+':
+			if e.project == "" {
+				e.project = w[1:]
+			}
+		case len(w) > 1 && w[0] == '@':
+			e.node.Tags = append(e.node.Tags, w[1:])
+		case hasValue && (key == "due" || key == "t"):
+			if _, err := time.Parse("2006-01-02", value); err != nil {
+				return todoEntry{}, fmt.Errorf("invalid date %q (want YYYY-MM-DD)", w)
+			}
+			if key == "due" {
+				e.node.DueDate = value
+			} else {
+				e.node.ScheduledOn = value
+			}
+		case hasValue && key == "pri":
+			p, err := model.ParsePriority(value)
+			if err != nil {
+				return todoEntry{}, err
+			}
+			e.node.Priority = p
+		default:
+			words = append(words, w)
+		}
+	}
+	e.node.Title = strings.Join(words, " ")
+	if e.node.Title == "" {
+		return todoEntry{}, fmt.Errorf("task title is required")
+	}
+	return e, nil
+}
+
+// isTodoMarker reports whether ParseTodoTxt takes w out of the title: a
+// "+project", an "@context" or a "due:", "t:" or "pri:" value.
+func isTodoMarker(w string) bool {
+	key, _, hasValue := strings.Cut(w, ":")
+	return len(w) > 1 && (w[0] == '+' || w[0] == '@') ||
+		hasValue && (key == "due" || key == "t" || key == "pri")
+}
+
+// projectName turns a task title into a todo.txt project name.
+func projectName(title string) string {
+	return strings.Join(strings.Fields(title), "-")
+}
+
+// WriteTodoTxt writes tasks as todo.txt lines, parents first. Sub-tasks get
+// their top-level ancestor as "+project"; tags become "@context" and
+// completed tasks keep their priority as "pri:". Title words that would
+// read as such markers, and words starting with a backslash, are escaped
+// with a backslash. In-progress status, descriptions and nesting below the
+// first level are not represented.
+func WriteTodoTxt(w io.Writer, tasks []model.Task) error {
+	byID := make(map[int]model.Task, len(tasks))
+	for _, t := range tasks {
+		byID[t.ID] = t
+	}
+	root := func(t model.Task) (model.Task, bool) {
+		found := false
+		for t.ParentID != nil {
+			p, ok := byID[*t.ParentID]
+			if !ok {
+				break
+			}
+			t, found = p, true
+		}
+		return t, found
+	}
+
+	for _, t := range parentsFirst(tasks) {
+		var parts []string
+		if t.Status == model.StatusCompleted {
+			parts = append(parts, "x", t.UpdatedAt.Format("2006-01-02"), t.CreatedAt.Format("2006-01-02"))
+		} else {
+			if p := t.PriorityLetter(); p != "" {
+				parts = append(parts, "("+p+")")
+			}
+			parts = append(parts, t.CreatedAt.Format("2006-01-02"))
+		}
+		for _, word := range strings.Fields(t.Title) {
+			if isTodoMarker(word) || word[0] == '\\' {
+				word = `\` + word
+			}
+			parts = append(parts, word)
+		}
+		if r, ok := root(t); ok {
+			parts = append(parts, "+"+projectName(r.Title))
+		}
+		for _, tag := range t.Tags {
+			parts = append(parts, "@"+strings.Join(strings.Fields(tag.Name), "-"))
+		}
+		if t.DueDate != nil {
+			parts = append(parts, "due:"+*t.DueDate)
+		}
+		if t.ScheduledOn != nil {
+			parts = append(parts, "t:"+*t.ScheduledOn)
+		}
+		if p := t.PriorityLetter(); p != "" && t.Status == model.StatusCompleted {
+			parts = append(parts, "pri:"+p)
+		}
+		if _, err := fmt.Fprintln(w, strings.Join(parts, " ")); err != nil {
+			return err
+		}
+	}
+	return nil
+}
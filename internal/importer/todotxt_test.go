@@ -0,0 +1,40 @@
+package importer
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestTodoTxtRoundTrip(t *testing.T) {
+	created := time.Date(2026, 10, 1, 9, 0, 0, 0, time.Local)
+	due := "2026-11-01"
+	one := 1
+	tasks := []model.Task{
+		{ID: 1, Title: "Plan trip", Priority: 1, DueDate: &due, CreatedAt: created, UpdatedAt: created,
+			Tags: []model.Tag{{Name: "home"}}},
+		{ID: 2, Title: `Ask about due:friday at @office for +bonus pri:high \n`, ParentID: &one,
+			Status: model.StatusCompleted, CreatedAt: created, UpdatedAt: created},
+	}
+	var buf bytes.Buffer
+	if err := WriteTodoTxt(&buf, tasks); err != nil {
+		t.Fatal(err)
+	}
+	nodes, err := ParseTodoTxt(buf.String())
+	if err != nil {
+		t.Fatalf("ParseTodoTxt(%q): %v", buf.String(), err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Children) != 1 {
+		t.Fatalf("nodes = %+v, want one task with one sub-task", nodes)
+	}
+	plan, ask := nodes[0], nodes[0].Children[0]
+	if plan.Title != "Plan trip" || plan.Priority != 1 || plan.DueDate != due || !slices.Equal(plan.Tags, []string{"home"}) {
+		t.Errorf("parent = %+v", plan)
+	}
+	if ask.Title != tasks[1].Title || ask.Status != model.StatusCompleted || len(ask.Tags) != 0 || ask.DueDate != "" || ask.Priority != 0 {
+		t.Errorf("sub-task = %+v", ask)
+	}
+}
@@ -2,20 +2,55 @@ package importer
 
 import (
 	"fmt"
+	"io"
+	"strconv"
+	"strings"
 
+	"github.com/nissyi-gh/flow/internal/model"
 	"github.com/nissyi-gh/flow/internal/store"
 	"gopkg.in/yaml.v3"
 )
 
 // YAMLTask represents a single task in the YAML input.
 type YAMLTask struct {
+	// ID names the task within the document for depends_on; Merge also
+	// matches IDs such as "flow-12" to existing tasks.
+	ID          string     `yaml:"id,omitempty"`
 	Title       string     `yaml:"title"`
 	Description string     `yaml:"description,omitempty"`
+	Status      string     `yaml:"status,omitempty"`
+	Priority    string     `yaml:"priority,omitempty"`
 	DueDate     string     `yaml:"due_date,omitempty"`
-	Tags        []string   `yaml:"tags,omitempty"`
+	ScheduledOn string     `yaml:"scheduled_on,omitempty"`
+	Tags        []YAMLTag  `yaml:"tags,omitempty"`
+	DependsOn   []string   `yaml:"depends_on,omitempty"`
 	Children    []YAMLTask `yaml:"children,omitempty"`
 }
 
+// YAMLTag is written either as a plain tag name or as a mapping with a name
+// and a color, e.g. {name: work, color: "205"}.
+type YAMLTag struct {
+	Name  string `yaml:"name"`
+	Color string `yaml:"color,omitempty"`
+}
+
+func (t *YAMLTag) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		t.Name, t.Color = n.Value, ""
+		return nil
+	}
+	type plain YAMLTag
+	return n.Decode((*plain)(t))
+}
+
+func (t YAMLTag) MarshalYAML() (any, error) {
+	if t.Color == "" {
+		return t.Name, nil
+	}
+	type plain YAMLTag
+	return plain(t), nil
+}
+
 // YAMLInput represents the root structure of the YAML input.
 type YAMLInput struct {
 	Tasks []YAMLTask `yaml:"tasks"`
@@ -25,96 +60,130 @@ type YAMLInput struct {
 // parentID can be nil for root-level tasks.
 // Returns the number of tasks created.
 func Import(s *store.TaskStore, yamlStr string, parentID *int) (int, error) {
+	nodes, err := ParseYAML(yamlStr)
+	if err != nil {
+		return 0, err
+	}
+	return Create(s, nodes, parentID)
+}
+
+// ParseYAML parses a YAML task list.
+func ParseYAML(yamlStr string) ([]Node, error) {
 	var input YAMLInput
 	if err := yaml.Unmarshal([]byte(yamlStr), &input); err != nil {
-		return 0, fmt.Errorf("YAML parse error: %w", err)
+		return nil, fmt.Errorf("YAML parse error: %w", err)
 	}
 
 	if len(input.Tasks) == 0 {
-		return 0, fmt.Errorf("no tasks found in YAML")
+		return nil, fmt.Errorf("no tasks found in YAML")
 	}
-
-	count := 0
-	for _, yt := range input.Tasks {
-		n, err := importTask(s, yt, parentID)
-		if err != nil {
-			return count, err
-		}
-		count += n
-	}
-	return count, nil
+	return yamlNodes(input.Tasks)
 }
 
-func importTask(s *store.TaskStore, yt YAMLTask, parentID *int) (int, error) {
-	if yt.Title == "" {
-		return 0, fmt.Errorf("task title is required")
-	}
-
-	task, err := s.Add(yt.Title, parentID)
-	if err != nil {
-		return 0, fmt.Errorf("add task %q: %w", yt.Title, err)
-	}
-	count := 1
-
-	if yt.Description != "" {
-		desc := yt.Description
-		if err := s.UpdateDescription(task.ID, &desc); err != nil {
-			return count, fmt.Errorf("set description for %q: %w", yt.Title, err)
+func yamlNodes(tasks []YAMLTask) ([]Node, error) {
+	nodes := make([]Node, len(tasks))
+	for i, yt := range tasks {
+		n := Node{
+			ID:          yt.ID,
+			Title:       yt.Title,
+			Description: yt.Description,
+			DueDate:     yt.DueDate,
+			ScheduledOn: yt.ScheduledOn,
+			DependsOn:   yt.DependsOn,
 		}
-	}
-
-	if yt.DueDate != "" {
-		dd := yt.DueDate
-		if err := s.SetDueDate(task.ID, &dd); err != nil {
-			return count, fmt.Errorf("set due date for %q: %w", yt.Title, err)
+		if yt.Status != "" {
+			status, err := model.ParseTaskStatus(strings.ToLower(yt.Status))
+			if err != nil {
+				return nil, fmt.Errorf("task %q: %w", yt.Title, err)
+			}
+			n.Status, n.StatusSet = status, true
 		}
-	}
-
-	if len(yt.Tags) > 0 {
-		if err := assignTags(s, task.ID, yt.Tags); err != nil {
-			return count, fmt.Errorf("assign tags for %q: %w", yt.Title, err)
+		if yt.Priority != "" {
+			priority, err := model.ParsePriority(strings.ToUpper(yt.Priority))
+			if err != nil {
+				return nil, fmt.Errorf("task %q: %w", yt.Title, err)
+			}
+			n.Priority = priority
 		}
-	}
-
-	for _, child := range yt.Children {
-		id := task.ID
-		n, err := importTask(s, child, &id)
+		for _, tag := range yt.Tags {
+			n.Tags = append(n.Tags, tag.Name)
+			if tag.Color != "" {
+				if n.TagColors == nil {
+					n.TagColors = make(map[string]string)
+				}
+				n.TagColors[tag.Name] = tag.Color
+			}
+		}
+		children, err := yamlNodes(yt.Children)
 		if err != nil {
-			return count, err
+			return nil, err
 		}
-		count += n
+		n.Children = children
+		nodes[i] = n
 	}
-
-	return count, nil
+	return nodes, nil
 }
 
-func assignTags(s *store.TaskStore, taskID int, tagNames []string) error {
-	existingTags, err := s.ListTags()
-	if err != nil {
-		return err
+// WriteYAML writes tasks in the format read by ParseYAML, nesting each task
+// under its parent; tasks whose parent is not in tasks become roots. The
+// task ID is written as an id such as "flow-12" and every status is written,
+// not_started included, so the file can be edited and merged back, and deps, the dependencies by task ID, become depends_on
+// links between the written tasks.
+func WriteYAML(w io.Writer, tasks []model.Task, deps map[int][]int) error {
+	present := make(map[int]bool, len(tasks))
+	for _, t := range tasks {
+		present[t.ID] = true
 	}
-
-	tagMap := make(map[string]int)
-	for _, t := range existingTags {
-		tagMap[t.Name] = t.ID
+	children := make(map[int][]model.Task)
+	var roots []model.Task
+	for _, t := range tasks {
+		if t.ParentID != nil && present[*t.ParentID] {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		} else {
+			roots = append(roots, t)
+		}
 	}
 
-	palette := []string{"39", "205", "148", "214", "141", "81", "203", "227"}
-
-	for _, name := range tagNames {
-		tagID, exists := tagMap[name]
-		if !exists {
-			color := palette[len(tagMap)%len(palette)]
-			tag, err := s.CreateTag(name, color)
-			if err != nil {
-				return fmt.Errorf("create tag %q: %w", name, err)
+	var build func(tasks []model.Task) []YAMLTask
+	build = func(tasks []model.Task) []YAMLTask {
+		out := make([]YAMLTask, len(tasks))
+		for i, t := range tasks {
+			yt := YAMLTask{
+				ID:          taskRef(t.ID),
+				Title:       t.Title,
+				Description: deref(t.Description),
+				Status:      t.Status.String(),
+				Priority:    t.PriorityLetter(),
+				DueDate:     deref(t.DueDate),
+				ScheduledOn: deref(t.ScheduledOn),
+				Children:    build(children[t.ID]),
 			}
-			tagID = tag.ID
-			tagMap[name] = tagID
-		}
-		if err := s.AssignTag(taskID, tagID); err != nil {
-			return fmt.Errorf("assign tag %q: %w", name, err)
+			for _, tag := range t.Tags {
+				yt.Tags = append(yt.Tags, YAMLTag{Name: tag.Name})
+				if !tag.Inherited {
+					yt.Tags[len(yt.Tags)-1].Color = tag.Color
+				}
+			}
+			for _, dep := range deps[t.ID] {
+				if present[dep] {
+					yt.DependsOn = append(yt.DependsOn, taskRef(dep))
+				}
+			}
+			out[i] = yt
 		}
+		return out
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(YAMLInput{Tasks: build(roots)}); err != nil {
+		return err
 	}
-	return nil
+	return enc.Close()
+}
+
+// taskRef returns the id that refers to the existing task id, as read by
+// nodeTaskID.
+func taskRef(id int) string {
+	return "flow-" + strconv.Itoa(id)
 }
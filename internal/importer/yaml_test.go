@@ -0,0 +1,55 @@
+package importer
+
+import (
+	"bytes"
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestYAMLRoundTrip(t *testing.T) {
+	due, scheduled, desc := "2026-11-01", "2026-10-20", "Steps:\n- [ ] pack\n- [x] book"
+	one := 1
+	tasks := []model.Task{
+		{ID: 1, Title: "Trip", Description: &desc, DueDate: &due, ScheduledOn: &scheduled,
+			Priority: 2, Status: model.StatusInProgress,
+			Tags: []model.Tag{{Name: "travel", Color: "205"}, {Name: "travel/eu", Color: "205", Inherited: true}}},
+		{ID: 2, Title: "Visa", ParentID: &one, Status: model.StatusCompleted},
+		{ID: 3, Title: "Flights", ParentID: &one},
+	}
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, tasks, map[int][]int{3: {2}}); err != nil {
+		t.Fatal(err)
+	}
+	if f := Detect(buf.String()); f != FormatYAML {
+		t.Fatalf("Detect = %s, want YAML", f)
+	}
+	nodes, err := ParseYAML(buf.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(nodes) != 1 || len(nodes[0].Children) != 2 {
+		t.Fatalf("nodes = %+v, want one task with two sub-tasks", nodes)
+	}
+
+	trip := nodes[0]
+	if trip.ID != "flow-1" || trip.Title != "Trip" || trip.Description != desc ||
+		trip.DueDate != due || trip.ScheduledOn != scheduled || trip.Priority != 2 ||
+		trip.Status != model.StatusInProgress || !trip.StatusSet {
+		t.Errorf("parent = %+v", trip)
+	}
+	if !slices.Equal(trip.Tags, []string{"travel", "travel/eu"}) ||
+		!maps.Equal(trip.TagColors, map[string]string{"travel": "205"}) {
+		t.Errorf("tags = %v, colors = %v; want the inherited color left out", trip.Tags, trip.TagColors)
+	}
+	visa, flights := trip.Children[0], trip.Children[1]
+	if visa.ID != "flow-2" || visa.Status != model.StatusCompleted {
+		t.Errorf("first sub-task = %+v", visa)
+	}
+	if flights.ID != "flow-3" || flights.Status != model.StatusNotStarted || !flights.StatusSet ||
+		!slices.Equal(flights.DependsOn, []string{"flow-2"}) {
+		t.Errorf("second sub-task = %+v", flights)
+	}
+}
@@ -0,0 +1,187 @@
+// Package llm sends prompts to OpenAI-compatible chat completion endpoints,
+// including local servers such as llama.cpp and Ollama, and streams the
+// replies.
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/config"
+)
+
+// Client calls the /chat/completions endpoint below Endpoint.
+type Client struct {
+	Endpoint    string
+	Model       string
+	APIKey      string
+	Temperature *float64
+	HTTPClient  *http.Client
+}
+
+// New returns a client for the configured endpoint. The timeout only covers
+// waiting for the response headers, so that a long reply can stream for as
+// long as it needs.
+func New(cfg config.LLM) *Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = cfg.Timeout
+	return &Client{
+		Endpoint:    strings.TrimRight(cfg.Endpoint, "/"),
+		Model:       cfg.Model,
+		APIKey:      cfg.Key(),
+		Temperature: cfg.Temperature,
+		HTTPClient:  &http.Client{Transport: transport},
+	}
+}
+
+// Error is returned when the endpoint responds with an error status.
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("llm: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+type message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type request struct {
+	Model       string    `json:"model,omitempty"`
+	Messages    []message `json:"messages"`
+	Stream      bool      `json:"stream"`
+	Temperature *float64  `json:"temperature,omitempty"`
+}
+
+// response covers both a streamed chunk, which carries a delta, and a
+// complete response, which carries a message.
+type response struct {
+	Choices []struct {
+		Delta   message `json:"delta"`
+		Message message `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// Complete sends prompt as a single user message and returns the reply.
+// The reply is streamed: onDelta, if not nil, is called with each piece of
+// text as it arrives. Servers that ignore streaming and answer with a
+// single JSON response are supported too.
+func (c *Client) Complete(ctx context.Context, prompt string, onDelta func(string)) (string, error) {
+	body, err := json.Marshal(request{
+		Model:       c.Model,
+		Messages:    []message{{Role: "user", Content: prompt}},
+		Stream:      true,
+		Temperature: c.Temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.Endpoint+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if c.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.APIKey)
+	}
+
+	httpClient := c.HTTPClient
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode/100 != 2 {
+		return "", responseError(resp)
+	}
+	if mt, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type")); mt == "application/json" {
+		return decodeResponse(resp.Body, onDelta)
+	}
+	return readStream(resp.Body, onDelta)
+}
+
+// readStream reads server-sent events until the [DONE] marker or the end of
+// the body.
+func readStream(r io.Reader, onDelta func(string)) (string, error) {
+	var sb strings.Builder
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		data, ok := strings.CutPrefix(sc.Text(), "data:")
+		if !ok {
+			continue // blank separators, comments and other fields
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+		var chunk response
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return sb.String(), fmt.Errorf("llm: malformed stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			return sb.String(), fmt.Errorf("llm: %s", chunk.Error.Message)
+		}
+		for _, ch := range chunk.Choices {
+			if ch.Delta.Content == "" {
+				continue
+			}
+			sb.WriteString(ch.Delta.Content)
+			if onDelta != nil {
+				onDelta(ch.Delta.Content)
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return sb.String(), err
+	}
+	return sb.String(), nil
+}
+
+func decodeResponse(r io.Reader, onDelta func(string)) (string, error) {
+	var res response
+	if err := json.NewDecoder(r).Decode(&res); err != nil {
+		return "", fmt.Errorf("llm: malformed response: %w", err)
+	}
+	if res.Error != nil {
+		return "", fmt.Errorf("llm: %s", res.Error.Message)
+	}
+	if len(res.Choices) == 0 {
+		return "", fmt.Errorf("llm: response has no choices")
+	}
+	text := res.Choices[0].Message.Content
+	if onDelta != nil && text != "" {
+		onDelta(text)
+	}
+	return text, nil
+}
+
+// responseError builds an *Error from an error response, using the
+// OpenAI-style {"error": {"message": ...}} body when there is one.
+func responseError(resp *http.Response) error {
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	msg := strings.TrimSpace(string(data))
+	var res response
+	if json.Unmarshal(data, &res) == nil && res.Error != nil {
+		msg = res.Error.Message
+	}
+	return &Error{StatusCode: resp.StatusCode, Message: msg}
+}
@@ -0,0 +1,158 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/config"
+)
+
+// newTestClient returns a client for a server answering every request
+// with h.
+func newTestClient(t *testing.T, h http.HandlerFunc) *Client {
+	t.Helper()
+	ts := httptest.NewServer(h)
+	t.Cleanup(ts.Close)
+	return &Client{Endpoint: ts.URL, Model: "test"}
+}
+
+// reply writes body with the given content type and status.
+func reply(contentType string, status int, body string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", contentType)
+		w.WriteHeader(status)
+		io.WriteString(w, body)
+	}
+}
+
+func TestCompleteStream(t *testing.T) {
+	var got request
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/chat/completions" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		json.NewDecoder(r.Body).Decode(&got)
+		reply("text/event-stream", http.StatusOK, strings.Join([]string{
+			`: keep-alive`,
+			`data: {"choices":[{"delta":{"role":"assistant"}}]}`,
+			``,
+			`data: {"choices":[{"delta":{"content":"Hel"}}]}`,
+			``,
+			`data:{"choices":[{"delta":{"content":"lo"}}]}`,
+			``,
+			`data: [DONE]`,
+			``,
+			`data: {"choices":[{"delta":{"content":" ignored"}}]}`,
+			``,
+		}, "\n"))(w, r)
+	})
+
+	var deltas []string
+	text, err := c.Complete(context.Background(), "hi", func(s string) { deltas = append(deltas, s) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello" {
+		t.Errorf("text = %q, want %q", text, "Hello")
+	}
+	if strings.Join(deltas, "|") != "Hel|lo" {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if !got.Stream || got.Model != "test" || len(got.Messages) != 1 || got.Messages[0].Content != "hi" {
+		t.Errorf("request = %+v", got)
+	}
+}
+
+func TestCompleteJSON(t *testing.T) {
+	c := newTestClient(t, reply("application/json; charset=utf-8", http.StatusOK,
+		`{"choices":[{"message":{"role":"assistant","content":"Hello"}}]}`))
+
+	var deltas []string
+	text, err := c.Complete(context.Background(), "hi", func(s string) { deltas = append(deltas, s) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if text != "Hello" || len(deltas) != 1 || deltas[0] != "Hello" {
+		t.Errorf("text = %q, deltas = %q", text, deltas)
+	}
+}
+
+func TestCompleteErrorStatus(t *testing.T) {
+	c := newTestClient(t, reply("application/json", http.StatusUnauthorized,
+		`{"error":{"message":"invalid api key"}}`))
+
+	_, err := c.Complete(context.Background(), "hi", nil)
+	var e *Error
+	if !errors.As(err, &e) {
+		t.Fatalf("err = %v, want *Error", err)
+	}
+	if e.StatusCode != http.StatusUnauthorized || e.Message != "invalid api key" {
+		t.Errorf("err = %+v", e)
+	}
+}
+
+func TestCompleteMalformedChunk(t *testing.T) {
+	c := newTestClient(t, reply("text/event-stream", http.StatusOK,
+		"data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\ndata: {not json\n\n"))
+
+	text, err := c.Complete(context.Background(), "hi", nil)
+	if err == nil || !strings.Contains(err.Error(), "malformed stream chunk") {
+		t.Errorf("err = %v, want a malformed chunk error", err)
+	}
+	if text != "Hel" {
+		t.Errorf("text = %q, want the text before the bad chunk", text)
+	}
+}
+
+func TestCompleteCancel(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Hel\"}}]}\n\n")
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	_, err := c.Complete(ctx, "hi", func(string) { cancel() })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestNewTimeout(t *testing.T) {
+	cfg := config.LLM{Model: "test", Timeout: 50 * time.Millisecond}
+
+	// A reply that streams for longer than the timeout still completes.
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, s := range []string{"Hel", "lo"} {
+			io.WriteString(w, `data: {"choices":[{"delta":{"content":"`+s+`"}}]}`+"\n\n")
+			w.(http.Flusher).Flush()
+			time.Sleep(2 * cfg.Timeout)
+		}
+	}))
+	t.Cleanup(ts.Close)
+	cfg.Endpoint = ts.URL
+	text, err := New(cfg).Complete(context.Background(), "hi", nil)
+	if err != nil || text != "Hello" {
+		t.Errorf("slow stream = %q, %v, want Hello", text, err)
+	}
+
+	// A server that does not start replying in time fails.
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(4 * cfg.Timeout)
+	}))
+	t.Cleanup(slow.Close)
+	cfg.Endpoint = slow.URL
+	if _, err := New(cfg).Complete(context.Background(), "hi", nil); err == nil {
+		t.Error("slow headers: no error, want a timeout")
+	}
+}
@@ -1,12 +1,60 @@
 package model
 
-import "time"
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TagSeparator separates the levels of a hierarchical tag name, e.g. "work/clientA".
+const TagSeparator = "/"
 
 // Tag represents a reusable label that can be attached to tasks.
 type Tag struct {
+	ID        int
+	Name      string
+	Color     string // lipgloss 256-color code, e.g. "205", "39", "148"
+	Inherited bool   // Color was taken from a parent tag
+}
+
+// ParentTagName returns the name of the tag enclosing name, or "" for a
+// top-level tag.
+func ParentTagName(name string) string {
+	i := strings.LastIndex(name, TagSeparator)
+	if i < 0 {
+		return ""
+	}
+	return name[:i]
+}
+
+// Depth returns the nesting level of the tag; top-level tags are 0.
+func (t Tag) Depth() int {
+	return strings.Count(t.Name, TagSeparator)
+}
+
+// Leaf returns the last segment of the tag name.
+func (t Tag) Leaf() string {
+	return t.Name[strings.LastIndex(t.Name, TagSeparator)+1:]
+}
+
+// Within reports whether the tag is name itself or nested below it.
+func (t Tag) Within(name string) bool {
+	return t.Name == name || strings.HasPrefix(t.Name, name+TagSeparator)
+}
+
+// TagPalette lists the colors given to new tags in turn.
+var TagPalette = []string{"39", "205", "148", "214", "141", "81", "203", "227"}
+
+// PaletteColor returns the color for a new tag created after n others.
+func PaletteColor(n int) string {
+	return TagPalette[n%len(TagPalette)]
+}
+
+// View is a named task query shown as a selectable view in the TUI.
+type View struct {
 	ID    int
 	Name  string
-	Color string // lipgloss 256-color code, e.g. "205", "39", "148"
+	Query string
 }
 
 // TaskStatus represents the progress state of a task.
@@ -18,6 +66,30 @@ const (
 	StatusCompleted   TaskStatus = 2
 )
 
+var statusNames = map[TaskStatus]string{
+	StatusNotStarted: "not_started",
+	StatusInProgress: "in_progress",
+	StatusCompleted:  "completed",
+}
+
+// String returns the status name used in exports, e.g. "in_progress".
+func (s TaskStatus) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return statusNames[StatusNotStarted]
+}
+
+// ParseTaskStatus converts a name produced by String back to a TaskStatus.
+func ParseTaskStatus(name string) (TaskStatus, error) {
+	for s, n := range statusNames {
+		if n == name {
+			return s, nil
+		}
+	}
+	return StatusNotStarted, fmt.Errorf("unknown status %q", name)
+}
+
 // Task represents a single task stored in the database.
 type Task struct {
 	ID          int
@@ -27,11 +99,34 @@ type Task struct {
 	Status      TaskStatus
 	ParentID    *int
 	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Version     int // incremented on every change, for optimistic concurrency
+	Priority    int // 0 for none, 1 (A, highest) to 26 (Z)
 	ScheduledOn *string
 	DueDate     *string
 	Tags        []Tag
 }
 
+// PriorityLetter returns the priority as "A" to "Z", or "" when unset.
+func (t Task) PriorityLetter() string {
+	if t.Priority < 1 || t.Priority > 26 {
+		return ""
+	}
+	return string(rune('A' + t.Priority - 1))
+}
+
+// ParsePriority converts a letter from "A" (highest) to "Z" into a
+// priority; "" yields 0, meaning no priority.
+func ParsePriority(letter string) (int, error) {
+	if letter == "" {
+		return 0, nil
+	}
+	if len(letter) != 1 || letter[0] < 'A' || letter[0] > 'Z' {
+		return 0, fmt.Errorf("invalid priority %q (want A-Z)", letter)
+	}
+	return int(letter[0]-'A') + 1, nil
+}
+
 // IsToday returns true if the task is scheduled for today.
 func (t Task) IsToday() bool {
 	if t.ScheduledOn == nil {
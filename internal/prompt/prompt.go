@@ -0,0 +1,333 @@
+// Package prompt renders the prompts that ask an AI assistant for task
+// breakdowns in the YAML import format. Prompts are text/template files:
+// English and Japanese defaults are built in, and files in the user's
+// prompt directory add new prompts or replace built-in ones by name.
+package prompt
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/*/*.tmpl
+var builtinFS embed.FS
+
+// builtin lists the built-in prompts in menu order.
+var builtin = []string{"new", "task", "context", "review", "daily", "rewrite"}
+
+// Data is passed to prompt templates.
+type Data struct {
+	Task      *model.Task  // the selected task, nil if there is none
+	Ancestors []model.Task // parents of Task, root first
+	Siblings  []model.Task // other tasks with the same parent as Task
+	Children  []model.Task
+	// Subtree is Task and all its descendants.
+	Subtree []OutlineTask
+	// Project is the top-level ancestor of Task and all its descendants.
+	Project []OutlineTask
+	// Agenda holds the open tasks that are overdue, due today or scheduled
+	// for today, with their ancestors for context.
+	Agenda  []OutlineTask
+	Tags    []model.Tag // every tag, so that the model can reuse them
+	Today   string      // YYYY-MM-DD
+	Request string      // what the user asked for, if anything
+}
+
+// OutlineTask is a task in an outline, in tree order.
+type OutlineTask struct {
+	model.Task
+	Depth int
+	// Context is set for tasks that are only listed as the ancestor of
+	// another task.
+	Context bool
+}
+
+// Collect gathers the data for a prompt about task, which may be nil.
+func Collect(s *store.TaskStore, task *model.Task) (Data, error) {
+	d := Data{Today: time.Now().Format("2006-01-02")}
+	tags, err := s.ListTags()
+	if err != nil {
+		return Data{}, err
+	}
+	d.Tags = tags
+	all, err := s.List()
+	if err != nil {
+		return Data{}, err
+	}
+	for i := range all {
+		all[i] = clean(all[i])
+	}
+	byID := make(map[int]model.Task, len(all))
+	children := make(map[int][]model.Task)
+	var roots []model.Task
+	for _, t := range all {
+		byID[t.ID] = t
+		if t.ParentID == nil {
+			roots = append(roots, t)
+		} else {
+			children[*t.ParentID] = append(children[*t.ParentID], t)
+		}
+	}
+
+	// outline lists tasks and their descendants in tree order, leaving out
+	// those keep rejects.
+	var outline func(tasks []model.Task, depth int, keep func(model.Task) bool) []OutlineTask
+	outline = func(tasks []model.Task, depth int, keep func(model.Task) bool) []OutlineTask {
+		var out []OutlineTask
+		for _, t := range tasks {
+			if keep(t) {
+				out = append(out, OutlineTask{Task: t, Depth: depth})
+				out = append(out, outline(children[t.ID], depth+1, keep)...)
+			}
+		}
+		return out
+	}
+
+	// inAgenda is true for agenda tasks and false for their ancestors.
+	inAgenda := make(map[int]bool)
+	for _, t := range all {
+		if t.Completed || !(t.IsOverdue() || t.IsDueToday() || t.IsToday()) {
+			continue
+		}
+		inAgenda[t.ID] = true
+		for pid := t.ParentID; pid != nil; pid = byID[*pid].ParentID {
+			if _, seen := inAgenda[*pid]; seen {
+				break
+			}
+			inAgenda[*pid] = false
+		}
+	}
+	d.Agenda = outline(roots, 0, func(t model.Task) bool {
+		_, ok := inAgenda[t.ID]
+		return ok
+	})
+	for i := range d.Agenda {
+		d.Agenda[i].Context = !inAgenda[d.Agenda[i].ID]
+	}
+
+	if task == nil {
+		return d, nil
+	}
+	t, ok := byID[task.ID]
+	if !ok {
+		t = clean(*task)
+	}
+	d.Task = &t
+	for pid := t.ParentID; pid != nil; pid = byID[*pid].ParentID {
+		d.Ancestors = append([]model.Task{byID[*pid]}, d.Ancestors...)
+	}
+	siblings := roots
+	if t.ParentID != nil {
+		siblings = children[*t.ParentID]
+	}
+	for _, sib := range siblings {
+		if sib.ID != t.ID {
+			d.Siblings = append(d.Siblings, sib)
+		}
+	}
+	d.Children = children[t.ID]
+	d.Subtree = outline([]model.Task{t}, 0, func(model.Task) bool { return true })
+	project := t
+	if len(d.Ancestors) > 0 {
+		project = d.Ancestors[0]
+	}
+	d.Project = outline([]model.Task{project}, 0, func(model.Task) bool { return true })
+	return d, nil
+}
+
+// clean clears empty optional fields, so that templates can test them
+// with a plain {{with}}.
+func clean(t model.Task) model.Task {
+	if t.Description != nil && strings.TrimSpace(*t.Description) == "" {
+		t.Description = nil
+	}
+	return t
+}
+
+// Template describes a prompt that can be rendered.
+type Template struct {
+	Name         string // file name without .tmpl
+	Title        string // shown in the menu
+	NeedsTask    bool   // the prompt is about the selected task
+	NeedsRequest bool   // the prompt uses a request typed by the user
+	// Import says where the YAML reply goes by default: "root" to add
+	// top-level tasks, "child" to add children of the selected task,
+	// "merge" to merge into those children or "merge-root" to merge into
+	// the top-level tasks; "rewrite" reviews the reply as a rewrite of the
+	// selected task's subtree, with renames, moves and deletions. If it is
+	// empty, prompts about a task merge and others add top-level tasks.
+	Import string
+}
+
+// importModes are the values of Template.Import.
+var importModes = []string{"root", "child", "merge", "merge-root", "rewrite"}
+
+// Set holds the prompts of one language together with the user's own.
+type Set struct {
+	list []Template
+	tmpl *template.Template
+}
+
+var funcs = template.FuncMap{
+	// tagNames lists tag names separated by commas.
+	"tagNames": func(tags []model.Tag) string {
+		names := make([]string, len(tags))
+		for i, t := range tags {
+			names[i] = t.Name
+		}
+		return strings.Join(names, ", ")
+	},
+	// indent returns two spaces per level.
+	"indent": func(depth int) string {
+		return strings.Repeat("  ", depth)
+	},
+}
+
+// Languages lists the languages of the built-in prompts.
+var Languages = []string{"en", "ja"}
+
+// Load returns the built-in prompts in lang, falling back to English, and
+// the *.tmpl files in dir. A missing dir is not an error. Files whose name
+// starts with "_" hold shared {{define}} blocks, such as the "format"
+// block describing the YAML format, and are not listed as prompts.
+func Load(lang, dir string) (*Set, error) {
+	if !slices.Contains(Languages, lang) {
+		lang = "en"
+	}
+	set := &Set{tmpl: template.New("").Funcs(funcs)}
+	root, err := fs.Sub(builtinFS, "templates/"+lang)
+	if err != nil {
+		return nil, err
+	}
+	if err := set.parseDir(root); err != nil {
+		return nil, err
+	}
+	slices.SortFunc(set.list, func(a, b Template) int {
+		return slices.Index(builtin, a.Name) - slices.Index(builtin, b.Name)
+	})
+
+	if dir == "" {
+		return set, nil
+	}
+	if err := set.parseDir(os.DirFS(dir)); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return set, nil
+		}
+		return nil, fmt.Errorf("%s: %w", dir, err)
+	}
+	return set, nil
+}
+
+// parseDir adds the templates in fsys, replacing those with the same name.
+func (s *Set) parseDir(fsys fs.FS) error {
+	files, err := fs.Glob(fsys, "*.tmpl")
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		// Glob does not report a missing directory.
+		if _, err := fs.Stat(fsys, "."); err != nil {
+			return err
+		}
+	}
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(file, ".tmpl")
+		if _, err := s.tmpl.New(name).Parse(string(data)); err != nil {
+			return err
+		}
+		if strings.HasPrefix(name, "_") {
+			continue
+		}
+		t, err := header(name, string(data))
+		if err != nil {
+			return fmt.Errorf("%s: %w", file, err)
+		}
+		if i := slices.IndexFunc(s.list, func(t Template) bool { return t.Name == name }); i >= 0 {
+			s.list[i] = t
+		} else {
+			s.list = append(s.list, t)
+		}
+	}
+	return nil
+}
+
+// headerComment matches a leading template comment, which may describe the
+// prompt in YAML:
+//
+//	{{/*
+//	title: Improve an existing task
+//	needs: [task]
+//	import: merge
+//	*/ -}}
+var headerComment = regexp.MustCompile(`^\s*\{\{-?\s*/\*((?s).*?)\*/\s*-?\}\}`)
+
+func header(name, text string) (Template, error) {
+	t := Template{Name: name, Title: name}
+	m := headerComment.FindStringSubmatch(text)
+	if m == nil {
+		return t, nil
+	}
+	var h struct {
+		Title  string   `yaml:"title"`
+		Needs  []string `yaml:"needs"`
+		Import string   `yaml:"import"`
+	}
+	// A comment that is not YAML is just a comment.
+	if yaml.Unmarshal([]byte(m[1]), &h) != nil {
+		return t, nil
+	}
+	if h.Title != "" {
+		t.Title = h.Title
+	}
+	if h.Import != "" && !slices.Contains(importModes, h.Import) {
+		return Template{}, fmt.Errorf("unknown import %q (want %s)", h.Import, strings.Join(importModes, ", "))
+	}
+	t.Import = h.Import
+	for _, need := range h.Needs {
+		switch need {
+		case "task":
+			t.NeedsTask = true
+		case "request":
+			t.NeedsRequest = true
+		default:
+			return Template{}, fmt.Errorf("unknown need %q (want task or request)", need)
+		}
+	}
+	return t, nil
+}
+
+// Templates returns the prompts in menu order.
+func (s *Set) Templates() []Template {
+	return s.list
+}
+
+// Render executes the named prompt with d.
+func (s *Set) Render(name string, d Data) (string, error) {
+	var sb strings.Builder
+	if err := s.tmpl.ExecuteTemplate(&sb, name, d); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()) + "\n", nil
+}
+
+// Dir returns the directory of user prompts below the config directory.
+func Dir(configDir string) string {
+	return filepath.Join(configDir, "prompts")
+}
@@ -0,0 +1,276 @@
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// newTestStore opens a store in a temporary directory.
+func newTestStore(t *testing.T) *store.TaskStore {
+	t.Helper()
+	s, err := store.NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// writeFiles writes the named files to a temporary directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, text := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func names(set *Set) []string {
+	var out []string
+	for _, tmpl := range set.Templates() {
+		out = append(out, tmpl.Name)
+	}
+	return out
+}
+
+func TestLoadUserTemplates(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"task.tmpl":  "{{/*\ntitle: My breakdown\nneeds: [task, request]\nimport: child\n*/ -}}\nMine: {{.Task.Title}} {{.Request}}",
+		"extra.tmpl": "{{/* just a note */}}Extra {{template \"format\"}}",
+	})
+	set, err := Load("en", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := names(set), append(slices.Clone(builtin), "extra"); !slices.Equal(got, want) {
+		t.Errorf("templates = %v, want %v", got, want)
+	}
+	task := set.Templates()[slices.Index(builtin, "task")]
+	if task.Title != "My breakdown" || !task.NeedsTask || !task.NeedsRequest || task.Import != "child" {
+		t.Errorf("task template = %+v", task)
+	}
+	out, err := set.Render("task", Data{Task: &model.Task{Title: "Trip"}, Request: "by Friday"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "Mine: Trip by Friday\n" {
+		t.Errorf("Render = %q", out)
+	}
+	extra := set.Templates()[len(builtin)]
+	if extra.Title != "extra" || extra.NeedsTask {
+		t.Errorf("extra template = %+v", extra)
+	}
+}
+
+func TestLoadRejectsHeaders(t *testing.T) {
+	for _, header := range []string{"needs: [tasks]", "import: replace"} {
+		dir := writeFiles(t, map[string]string{"bad.tmpl": "{{/*\n" + header + "\n*/}}text"})
+		if _, err := Load("en", dir); err == nil || !strings.Contains(err.Error(), "bad.tmpl") {
+			t.Errorf("Load with %q = %v, want an error naming bad.tmpl", header, err)
+		}
+	}
+}
+
+func TestLoadLanguages(t *testing.T) {
+	titles := func(lang string) []string {
+		set, err := Load(lang, filepath.Join(t.TempDir(), "missing"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var out []string
+		for _, tmpl := range set.Templates() {
+			out = append(out, tmpl.Title)
+		}
+		return out
+	}
+	en, ja, fr := titles("en"), titles("ja"), titles("fr")
+	if len(en) != len(builtin) || len(ja) != len(builtin) {
+		t.Fatalf("en = %v, ja = %v, want %d templates each", en, ja, len(builtin))
+	}
+	if slices.Equal(en, ja) {
+		t.Errorf("ja titles = %v, want them translated", ja)
+	}
+	if !slices.Equal(fr, en) {
+		t.Errorf("fr titles = %v, want the English ones %v", fr, en)
+	}
+}
+
+func TestBuiltinTemplatesRender(t *testing.T) {
+	s := newTestStore(t)
+	trip, err := s.Add("Trip", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	visa, err := s.Add("Visa", &trip.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("Flights", &trip.ID); err != nil {
+		t.Fatal(err)
+	}
+	today := "2026-01-01"
+	if err := s.SetDueDate(visa.ID, &today); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.EnsureTag("travel", "39"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, lang := range Languages {
+		set, err := Load(lang, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, withTask := range []bool{false, true} {
+			var task *model.Task
+			if withTask {
+				task = &visa
+			}
+			d, err := Collect(s, task)
+			if err != nil {
+				t.Fatal(err)
+			}
+			d.Request = "plan it"
+			for _, tmpl := range set.Templates() {
+				if tmpl.NeedsTask && !withTask {
+					continue
+				}
+				out, err := set.Render(tmpl.Name, d)
+				if err != nil {
+					t.Errorf("%s/%s (task %v): %v", lang, tmpl.Name, withTask, err)
+					continue
+				}
+				if strings.Contains(out, "<no value>") || !strings.Contains(out, "title:") {
+					t.Errorf("%s/%s (task %v) = %q", lang, tmpl.Name, withTask, out)
+				}
+			}
+		}
+	}
+}
+
+// outlineString renders an outline as "title:depth" pairs, marking context
+// tasks with a star.
+func outlineString(tasks []OutlineTask) string {
+	var parts []string
+	for _, t := range tasks {
+		s := fmt.Sprintf("%s:%d", t.Title, t.Depth)
+		if t.Context {
+			s += "*"
+		}
+		parts = append(parts, s)
+	}
+	return strings.Join(parts, " ")
+}
+
+// taskTitles joins the titles of tasks with spaces.
+func taskTitles(tasks []model.Task) string {
+	var out []string
+	for _, t := range tasks {
+		out = append(out, t.Title)
+	}
+	return strings.Join(out, " ")
+}
+
+func TestCollect(t *testing.T) {
+	s := newTestStore(t)
+	add := func(title string, parentID *int) model.Task {
+		t.Helper()
+		task, err := s.Add(title, parentID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return task
+	}
+	p := add("P", nil)
+	a := add("A", &p.ID)
+	a1 := add("A1", &a.ID)
+	add("B", &p.ID)
+	q := add("Q", nil)
+	add("R", nil)
+
+	past := time.Now().AddDate(0, 0, -3).Format("2006-01-02")
+	today := time.Now().Format("2006-01-02")
+	for _, err := range []error{
+		s.SetDueDate(a.ID, &past),
+		s.SetScheduledOn(a1.ID, &today),
+		s.SetDueDate(q.ID, &past),
+		s.UpdateStatus(q.ID, model.StatusCompleted),
+		s.UpdateStatus(a.ID, model.StatusInProgress),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	d, err := Collect(s, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := outlineString(d.Agenda), "P:0* A:1 A1:2"; got != want {
+		t.Errorf("Agenda = %s, want %s", got, want)
+	}
+	if d.Task != nil || d.Siblings != nil || d.Project != nil {
+		t.Errorf("Collect without a task = %+v", d)
+	}
+
+	d, err = Collect(s, &a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskTitles(d.Ancestors); got != "P" {
+		t.Errorf("Ancestors = %s, want P", got)
+	}
+	if got := taskTitles(d.Siblings); got != "B" {
+		t.Errorf("Siblings = %s, want B", got)
+	}
+	if got := taskTitles(d.Children); got != "A1" {
+		t.Errorf("Children = %s, want A1", got)
+	}
+	if got, want := outlineString(d.Subtree), "A:0 A1:1"; got != want {
+		t.Errorf("Subtree = %s, want %s", got, want)
+	}
+	if got, want := outlineString(d.Project), "P:0 A:1 A1:2 B:1"; got != want {
+		t.Errorf("Project = %s, want %s", got, want)
+	}
+
+	d, err = Collect(s, &q)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := taskTitles(d.Siblings); got != "P R" {
+		t.Errorf("siblings of a root = %s, want the other roots P R", got)
+	}
+	if d.Ancestors != nil {
+		t.Errorf("Ancestors of a root = %v", d.Ancestors)
+	}
+
+	set, err := Load("ja", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := add("C", &p.ID)
+	d, err = Collect(s, &c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := set.Render("context", d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"- A (進行中)", "- B (未着手)"} {
+		if !strings.Contains(out, line) {
+			t.Errorf("ja context prompt lacks %q:\n%s", line, out)
+		}
+	}
+}
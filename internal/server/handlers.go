@@ -0,0 +1,384 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// GET /tasks?q=QUERY&tree=1
+func (srv *Server) listTasks(r *http.Request) (int, any, error) {
+	tree, _ := strconv.ParseBool(r.URL.Query().Get("tree"))
+	list, err := srv.taskList(r.URL.Query().Get("q"), tree)
+	return http.StatusOK, list, err
+}
+
+// GET /tree
+func (srv *Server) tree(r *http.Request) (int, any, error) {
+	list, err := srv.taskList("", true)
+	return http.StatusOK, list, err
+}
+
+// GET /search?q=QUERY
+func (srv *Server) search(r *http.Request) (int, any, error) {
+	list, err := srv.taskList(r.URL.Query().Get("q"), false)
+	return http.StatusOK, list, err
+}
+
+// GET /tasks/{id}
+func (srv *Server) getTask(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusOK, doc, err
+}
+
+// POST /tasks
+func (srv *Server) createTask(r *http.Request) (int, any, error) {
+	var in api.TaskInput
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.Title == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "title is required")
+	}
+	patch := store.TaskPatch{
+		Description: in.Description,
+		DueDate:     in.DueDate,
+		ScheduledOn: in.ScheduledOn,
+	}
+	if err := validDate("due_date", patch.DueDate); err != nil {
+		return 0, nil, err
+	}
+	if err := validDate("scheduled_on", patch.ScheduledOn); err != nil {
+		return 0, nil, err
+	}
+	if in.Status != "" {
+		status, err := model.ParseTaskStatus(in.Status)
+		if err != nil {
+			return 0, nil, errorf(http.StatusBadRequest, "%v", err)
+		}
+		patch.Status = &status
+	}
+	if len(in.Tags) > 0 {
+		color, err := srv.newTagColor()
+		if err != nil {
+			return 0, nil, err
+		}
+		patch.Tags, patch.TagColor = &in.Tags, color
+	}
+	if in.ParentID != nil {
+		if _, err := srv.store.GetByID(*in.ParentID); err != nil {
+			return 0, nil, errorf(http.StatusBadRequest, "parent task %d not found", *in.ParentID)
+		}
+	}
+
+	var id int
+	err := srv.store.WithTx(func(tx *store.TaskStore) error {
+		t, err := tx.Add(in.Title, in.ParentID)
+		if err != nil {
+			return err
+		}
+		id = t.ID
+		if patch == (store.TaskPatch{}) {
+			return nil
+		}
+		_, err = tx.UpdateTask(t.ID, 0, patch)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusCreated, doc, err
+}
+
+// PATCH /tasks/{id}
+func (srv *Server) updateTask(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	var in api.TaskPatch
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	version, err := expectedVersion(r, in.Version)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	var patch store.TaskPatch
+	if in.Title.Set {
+		if in.Title.Null || in.Title.Value == "" {
+			return 0, nil, errorf(http.StatusBadRequest, "title must not be empty")
+		}
+		patch.Title = &in.Title.Value
+	}
+	if in.Status.Set {
+		status, err := model.ParseTaskStatus(in.Status.Value)
+		if err != nil {
+			return 0, nil, errorf(http.StatusBadRequest, "%v", err)
+		}
+		patch.Status = &status
+	}
+	if in.Description.Set {
+		patch.ClearDescription = in.Description.Null
+		if !in.Description.Null {
+			patch.Description = &in.Description.Value
+		}
+	}
+	if in.DueDate.Set {
+		patch.ClearDueDate = in.DueDate.Null
+		if !in.DueDate.Null {
+			patch.DueDate = &in.DueDate.Value
+			if err := validDate("due_date", patch.DueDate); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+	if in.ScheduledOn.Set {
+		patch.ClearScheduledOn = in.ScheduledOn.Null
+		if !in.ScheduledOn.Null {
+			patch.ScheduledOn = &in.ScheduledOn.Value
+			if err := validDate("scheduled_on", patch.ScheduledOn); err != nil {
+				return 0, nil, err
+			}
+		}
+	}
+	if in.ParentID.Set {
+		patch.ClearParent = in.ParentID.Null
+		if !in.ParentID.Null {
+			patch.ParentID = &in.ParentID.Value
+			if _, err := srv.store.GetByID(in.ParentID.Value); err != nil {
+				return 0, nil, errorf(http.StatusBadRequest, "parent task %d not found", in.ParentID.Value)
+			}
+		}
+	}
+	if in.Tags.Set {
+		tags := in.Tags.Value
+		color, err := srv.newTagColor()
+		if err != nil {
+			return 0, nil, err
+		}
+		patch.Tags, patch.TagColor = &tags, color
+	}
+
+	if _, err := srv.store.UpdateTask(id, version, patch); err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusOK, doc, err
+}
+
+// DELETE /tasks/{id}?recursive=1
+func (srv *Server) deleteTask(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	version, err := expectedVersion(r, 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	recursive, _ := strconv.ParseBool(r.URL.Query().Get("recursive"))
+
+	err = srv.store.WithTx(func(tx *store.TaskStore) error {
+		t, err := tx.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && t.Version != version {
+			return store.ErrVersionConflict
+		}
+		hasChildren, err := tx.HasChildren(id)
+		if err != nil {
+			return err
+		}
+		if hasChildren && !recursive {
+			return errorf(http.StatusConflict, "task %d has sub-tasks; pass recursive=1 to delete them too", id)
+		}
+		return tx.Delete(id)
+	})
+	return http.StatusNoContent, nil, err
+}
+
+// PUT /tasks/{id}/status
+func (srv *Server) setStatus(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	var in api.StatusInput
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	status, err := model.ParseTaskStatus(in.Status)
+	if err != nil {
+		return 0, nil, errorf(http.StatusBadRequest, "%v", err)
+	}
+	version, err := expectedVersion(r, 0)
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := srv.store.UpdateTask(id, version, store.TaskPatch{Status: &status}); err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusOK, doc, err
+}
+
+// POST /tasks/{id}/tags
+func (srv *Server) addTaskTag(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	var in api.TagInput
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.Name == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "name is required")
+	}
+	if in.Color == "" {
+		if in.Color, err = srv.newTagColor(); err != nil {
+			return 0, nil, err
+		}
+	}
+	err = srv.store.WithTx(func(tx *store.TaskStore) error {
+		if _, err := tx.GetByID(id); err != nil {
+			return err
+		}
+		tag, err := tx.EnsureTag(in.Name, in.Color)
+		if err != nil {
+			return errorf(http.StatusBadRequest, "%v", err)
+		}
+		return tx.AssignTag(id, tag.ID)
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusOK, doc, err
+}
+
+// DELETE /tasks/{id}/tags/{name...}
+func (srv *Server) removeTaskTag(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	tag, err := srv.store.TagByName(r.PathValue("name"))
+	if err != nil {
+		return 0, nil, err
+	}
+	if _, err := srv.store.GetByID(id); err != nil {
+		return 0, nil, err
+	}
+	if err := srv.store.UnassignTag(id, tag.ID); err != nil {
+		return 0, nil, err
+	}
+	doc, err := srv.taskDocument(id)
+	return http.StatusOK, doc, err
+}
+
+// GET /tags
+func (srv *Server) listTags(r *http.Request) (int, any, error) {
+	tags, err := srv.store.ListTags()
+	if err != nil {
+		return 0, nil, err
+	}
+	usage, err := srv.store.TagUsage()
+	if err != nil {
+		return 0, nil, err
+	}
+	list := api.TagList{SchemaVersion: api.SchemaVersion, Tags: []api.TagUsage{}}
+	for _, t := range tags {
+		list.Tags = append(list.Tags, api.TagUsage{Tag: api.NewTag(t), Tasks: usage[t.ID]})
+	}
+	return http.StatusOK, list, nil
+}
+
+// POST /tags
+func (srv *Server) createTag(r *http.Request) (int, any, error) {
+	var in api.TagInput
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	if in.Name == "" {
+		return 0, nil, errorf(http.StatusBadRequest, "name is required")
+	}
+	if _, err := srv.store.TagByName(in.Name); err == nil {
+		return 0, nil, errorf(http.StatusConflict, "tag %q already exists", in.Name)
+	}
+	color := in.Color
+	if color == "" {
+		var err error
+		if color, err = srv.newTagColor(); err != nil {
+			return 0, nil, err
+		}
+	}
+	tag, err := srv.store.EnsureTag(in.Name, color)
+	if err != nil {
+		return 0, nil, errorf(http.StatusBadRequest, "%v", err)
+	}
+	if in.Color != "" && tag.Depth() > 0 {
+		if err := srv.store.SetTagColor(tag.ID, in.Color); err != nil {
+			return 0, nil, err
+		}
+		tag.Color, tag.Inherited = in.Color, false
+	}
+	return http.StatusCreated, api.TagDocument{SchemaVersion: api.SchemaVersion, Tag: api.NewTag(tag)}, nil
+}
+
+// PATCH /tags/{id}
+func (srv *Server) updateTag(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	var in api.TagInput
+	if err := decode(r, &in); err != nil {
+		return 0, nil, err
+	}
+	var tag model.Tag
+	err = srv.store.WithTx(func(tx *store.TaskStore) error {
+		if in.Name != "" {
+			if err := tx.RenameTag(id, in.Name); err != nil {
+				return err
+			}
+		}
+		if in.Color != "" {
+			if err := tx.SetTagColor(id, in.Color); err != nil {
+				return err
+			}
+		}
+		tag, err = tx.TagByID(id)
+		return err
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return http.StatusOK, api.TagDocument{SchemaVersion: api.SchemaVersion, Tag: api.NewTag(tag)}, nil
+}
+
+// DELETE /tags/{id}
+func (srv *Server) deleteTag(r *http.Request) (int, any, error) {
+	id, err := pathID(r)
+	if err != nil {
+		return 0, nil, err
+	}
+	err = srv.store.WithTx(func(tx *store.TaskStore) error {
+		if _, err := tx.TagByID(id); err != nil {
+			return err
+		}
+		return tx.DeleteTag(id)
+	})
+	return http.StatusNoContent, nil, err
+}
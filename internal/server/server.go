@@ -0,0 +1,241 @@
+// Package server exposes a TaskStore as a local HTTP/JSON API. Request and
+// response bodies use the types of the api package.
+//
+// The API has no authentication. To keep web pages from reaching it through
+// the browser, request bodies must be sent as application/json, which a
+// cross-origin form cannot do without a CORS preflight, and the Host header
+// must name the listen address or a loopback host, which defeats DNS
+// rebinding.
+package server
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nissyi-gh/flow/api"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// Prefix is the path prefix of every API route.
+const Prefix = "/api/v1"
+
+// Server handles API requests. Store access is serialized so that
+// concurrent requests never interleave inside SQLite.
+type Server struct {
+	store *store.TaskStore
+	host  string // host of the listen address
+	mu    sync.Mutex
+	mux   *http.ServeMux
+}
+
+// New returns a server for s listening on addr, such as "127.0.0.1:7070",
+// with all routes registered.
+func New(s *store.TaskStore, addr string) *Server {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		host = addr
+	}
+	srv := &Server{store: s, host: host, mux: http.NewServeMux()}
+	routes := map[string]func(*http.Request) (int, any, error){
+		"GET /tasks":                        srv.listTasks,
+		"POST /tasks":                       srv.createTask,
+		"GET /tasks/{id}":                   srv.getTask,
+		"PATCH /tasks/{id}":                 srv.updateTask,
+		"DELETE /tasks/{id}":                srv.deleteTask,
+		"PUT /tasks/{id}/status":            srv.setStatus,
+		"POST /tasks/{id}/tags":             srv.addTaskTag,
+		"DELETE /tasks/{id}/tags/{name...}": srv.removeTaskTag,
+		"GET /tree":                         srv.tree,
+		"GET /search":                       srv.search,
+		"GET /tags":                         srv.listTags,
+		"POST /tags":                        srv.createTag,
+		"PATCH /tags/{id}":                  srv.updateTag,
+		"DELETE /tags/{id}":                 srv.deleteTag,
+	}
+	for pattern, h := range routes {
+		method, path, _ := strings.Cut(pattern, " ")
+		srv.mux.Handle(method+" "+Prefix+path, srv.handle(h))
+	}
+	return srv
+}
+
+func (srv *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !srv.allowedHost(r.Host) {
+		writeError(w, errorf(http.StatusForbidden, "host %q is not allowed", r.Host))
+		return
+	}
+	srv.mux.ServeHTTP(w, r)
+}
+
+// allowedHost reports whether a Host header names the listen address or a
+// loopback host. Wildcard listen addresses such as 0.0.0.0 only allow
+// loopback hosts.
+func (srv *Server) allowedHost(hostport string) bool {
+	host, _, err := net.SplitHostPort(hostport)
+	if err != nil {
+		host = hostport
+	}
+	host = strings.TrimSuffix(strings.Trim(host, "[]"), ".")
+	if strings.EqualFold(host, "localhost") {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return true
+	}
+	if ip := net.ParseIP(srv.host); srv.host == "" || ip != nil && ip.IsUnspecified() {
+		return false
+	}
+	return strings.EqualFold(host, srv.host)
+}
+
+// httpError carries the status code for an error response.
+type httpError struct {
+	status int
+	err    error
+}
+
+func (e httpError) Error() string { return e.err.Error() }
+
+func errorf(status int, format string, args ...any) error {
+	return httpError{status: status, err: fmt.Errorf(format, args...)}
+}
+
+// handle adapts a handler returning (status, body, error) to http.Handler.
+// Task bodies also set an ETag carrying the task version.
+func (srv *Server) handle(h func(*http.Request) (int, any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		srv.mu.Lock()
+		status, body, err := h(r)
+		srv.mu.Unlock()
+
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if doc, ok := body.(api.TaskDocument); ok {
+			w.Header().Set("ETag", etag(doc.Task.Version))
+		}
+		if body == nil {
+			w.WriteHeader(status)
+			return
+		}
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	})
+}
+
+// writeError responds with err and the status code it maps to.
+func writeError(w http.ResponseWriter, err error) {
+	var status int
+	var he httpError
+	switch {
+	case errors.As(err, &he):
+		status = he.status
+	case errors.Is(err, sql.ErrNoRows):
+		status = http.StatusNotFound
+	case errors.Is(err, store.ErrVersionConflict):
+		status = http.StatusPreconditionFailed
+	case errors.Is(err, store.ErrParentCycle), errors.Is(err, store.ErrInvalidTagName):
+		status = http.StatusBadRequest
+	default:
+		status = http.StatusInternalServerError
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(api.Error{Error: err.Error()})
+}
+
+func etag(version int) string {
+	return `"` + strconv.Itoa(version) + `"`
+}
+
+// expectedVersion returns the version required by If-Match, or fallback.
+func expectedVersion(r *http.Request, fallback int) (int, error) {
+	match := strings.TrimSpace(r.Header.Get("If-Match"))
+	if match == "" || match == "*" {
+		return fallback, nil
+	}
+	v, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(match, "W/"), `"`))
+	if err != nil {
+		return 0, errorf(http.StatusBadRequest, "invalid If-Match header %q", match)
+	}
+	return v, nil
+}
+
+func decode(r *http.Request, v any) error {
+	// Browsers send text/plain and form bodies cross-origin without asking.
+	if mt, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err != nil || mt != "application/json" {
+		return errorf(http.StatusUnsupportedMediaType, "request body must be application/json")
+	}
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return errorf(http.StatusBadRequest, "invalid request body: %v", err)
+	}
+	return nil
+}
+
+func pathID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id <= 0 {
+		return 0, errorf(http.StatusBadRequest, "invalid id %q", r.PathValue("id"))
+	}
+	return id, nil
+}
+
+func (srv *Server) taskDocument(id int) (api.TaskDocument, error) {
+	t, err := srv.store.GetByID(id)
+	if err != nil {
+		return api.TaskDocument{}, err
+	}
+	all, err := srv.store.List()
+	if err != nil {
+		return api.TaskDocument{}, err
+	}
+	return api.TaskDocument{
+		SchemaVersion: api.SchemaVersion,
+		Task:          api.NewTaskList([]model.Task{t}, all, false).Tasks[0],
+	}, nil
+}
+
+func (srv *Server) taskList(query string, tree bool) (api.TaskList, error) {
+	tasks, err := srv.store.Search(query)
+	if err != nil {
+		return api.TaskList{}, errorf(http.StatusBadRequest, "%v", err)
+	}
+	all, err := srv.store.List()
+	if err != nil {
+		return api.TaskList{}, err
+	}
+	return api.NewTaskList(tasks, all, tree), nil
+}
+
+func (srv *Server) newTagColor() (string, error) {
+	tags, err := srv.store.ListTags()
+	if err != nil {
+		return "", err
+	}
+	return model.PaletteColor(len(tags)), nil
+}
+
+func validDate(field string, v *string) error {
+	if v == nil {
+		return nil
+	}
+	d, err := store.ResolveDate(*v)
+	if err != nil {
+		return errorf(http.StatusBadRequest, "%s: %v", field, err)
+	}
+	*v = d
+	return nil
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// newTestServer returns a server for a store in a temporary directory.
+func newTestServer(t *testing.T, addr string) *Server {
+	t.Helper()
+	s, err := store.NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return New(s, addr)
+}
+
+// serve sends a request to srv and returns the response status.
+func serve(srv *Server, method, host, contentType, body string) int {
+	req := httptest.NewRequest(method, Prefix+"/tasks", strings.NewReader(body))
+	req.Host = host
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestContentType(t *testing.T) {
+	srv := newTestServer(t, "127.0.0.1:7070")
+	for _, tc := range []struct {
+		contentType string
+		want        int
+	}{
+		{"application/json", http.StatusCreated},
+		{"application/json; charset=utf-8", http.StatusCreated},
+		{"text/plain", http.StatusUnsupportedMediaType},
+		{"application/x-www-form-urlencoded", http.StatusUnsupportedMediaType},
+		{"", http.StatusUnsupportedMediaType},
+	} {
+		got := serve(srv, http.MethodPost, "127.0.0.1:7070", tc.contentType, `{"title":"a"}`)
+		if got != tc.want {
+			t.Errorf("POST with Content-Type %q = %d, want %d", tc.contentType, got, tc.want)
+		}
+	}
+}
+
+func TestHost(t *testing.T) {
+	for _, tc := range []struct {
+		addr, host string
+		want       int
+	}{
+		{"127.0.0.1:7070", "127.0.0.1:7070", http.StatusOK},
+		{"127.0.0.1:7070", "localhost:7070", http.StatusOK},
+		{"127.0.0.1:7070", "[::1]:7070", http.StatusOK},
+		{"127.0.0.1:7070", "evil.example:7070", http.StatusForbidden},
+		{"127.0.0.1:7070", "", http.StatusForbidden},
+		{"flow.lan:7070", "flow.lan:7070", http.StatusOK},
+		{"flow.lan:7070", "evil.example", http.StatusForbidden},
+		{"0.0.0.0:7070", "0.0.0.0:7070", http.StatusForbidden},
+		{"0.0.0.0:7070", "localhost:7070", http.StatusOK},
+	} {
+		srv := newTestServer(t, tc.addr)
+		if got := serve(srv, http.MethodGet, tc.host, "", ""); got != tc.want {
+			t.Errorf("listening on %s, GET with Host %q = %d, want %d", tc.addr, tc.host, got, tc.want)
+		}
+	}
+}
+
+// roundTrip sends a JSON request to srv and returns the response.
+func roundTrip(srv *Server, method, path, ifMatch, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, Prefix+path, strings.NewReader(body))
+	req.Host = "127.0.0.1:7070"
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ifMatch != "" {
+		req.Header.Set("If-Match", ifMatch)
+	}
+	w := httptest.NewRecorder()
+	srv.ServeHTTP(w, req)
+	return w
+}
+
+func TestVersionConflict(t *testing.T) {
+	srv := newTestServer(t, "127.0.0.1:7070")
+	w := roundTrip(srv, http.MethodPost, "/tasks", "", `{"title":"a"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create = %d %s", w.Code, w.Body)
+	}
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("create returned no ETag")
+	}
+
+	w = roundTrip(srv, http.MethodPatch, "/tasks/1", etag, `{"title":"b"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("update with current ETag = %d %s", w.Code, w.Body)
+	}
+	if w.Header().Get("ETag") == etag {
+		t.Errorf("update kept ETag %s", etag)
+	}
+	w = roundTrip(srv, http.MethodPatch, "/tasks/1", etag, `{"title":"c"}`)
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("update with stale ETag = %d, want 412", w.Code)
+	}
+	w = roundTrip(srv, http.MethodDelete, "/tasks/1", etag, "")
+	if w.Code != http.StatusPreconditionFailed {
+		t.Errorf("delete with stale ETag = %d, want 412", w.Code)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	srv := newTestServer(t, "127.0.0.1:7070")
+	for _, tc := range []struct{ method, path, body string }{
+		{http.MethodGet, "/tasks/9", ""},
+		{http.MethodPatch, "/tasks/9", `{"title":"b"}`},
+		{http.MethodDelete, "/tasks/9", ""},
+		{http.MethodPatch, "/tags/9", `{"color":"39"}`},
+		{http.MethodDelete, "/tags/9", ""},
+	} {
+		w := roundTrip(srv, tc.method, tc.path, "", tc.body)
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s %s = %d, want 404", tc.method, tc.path, w.Code)
+		}
+	}
+}
+
+func TestInvalidTagName(t *testing.T) {
+	srv := newTestServer(t, "127.0.0.1:7070")
+	w := roundTrip(srv, http.MethodPost, "/tags", "", `{"name":"work/child"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create = %d %s", w.Code, w.Body)
+	}
+	for _, name := range []string{"a//b", "x/", " "} {
+		w := roundTrip(srv, http.MethodPatch, "/tags/1", "", `{"name":"`+name+`"}`)
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("rename to %q = %d, want 400", name, w.Code)
+		}
+	}
+}
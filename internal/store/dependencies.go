@@ -0,0 +1,87 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrDependencyCycle is returned by AddDependency when the task would end up
+// depending on itself.
+var ErrDependencyCycle = errors.New("dependency would form a cycle")
+
+// AddDependency records that taskID cannot start before dependsOnID is
+// done. Adding an existing dependency succeeds without changes.
+func (s *TaskStore) AddDependency(taskID, dependsOnID int) error {
+	if taskID == dependsOnID {
+		return fmt.Errorf("task %d depends on itself: %w", taskID, ErrDependencyCycle)
+	}
+	// dependsOnID must not already depend on taskID, directly or not.
+	var cycle bool
+	err := s.q.QueryRow(`WITH RECURSIVE deps(id) AS (
+			SELECT depends_on_id FROM task_dependencies WHERE task_id = ?
+			UNION
+			SELECT d.depends_on_id FROM task_dependencies d JOIN deps ON d.task_id = deps.id
+		)
+		SELECT EXISTS (SELECT 1 FROM deps WHERE id = ?)`, dependsOnID, taskID).Scan(&cycle)
+	if err != nil {
+		return fmt.Errorf("check dependencies of task %d: %w", dependsOnID, err)
+	}
+	if cycle {
+		return fmt.Errorf("task %d on %d: %w", taskID, dependsOnID, ErrDependencyCycle)
+	}
+
+	res, err := s.q.Exec("INSERT OR IGNORE INTO task_dependencies (task_id, depends_on_id) VALUES (?, ?)", taskID, dependsOnID)
+	if err != nil {
+		return fmt.Errorf("add dependency of task %d on %d: %w", taskID, dependsOnID, err)
+	}
+	return s.touchIfChanged(taskID, res)
+}
+
+// RemoveDependency deletes a dependency added with AddDependency.
+func (s *TaskStore) RemoveDependency(taskID, dependsOnID int) error {
+	res, err := s.q.Exec("DELETE FROM task_dependencies WHERE task_id = ? AND depends_on_id = ?", taskID, dependsOnID)
+	if err != nil {
+		return fmt.Errorf("remove dependency of task %d on %d: %w", taskID, dependsOnID, err)
+	}
+	return s.touchIfChanged(taskID, res)
+}
+
+// Dependencies returns the IDs of the tasks taskID depends on, in
+// ascending order.
+func (s *TaskStore) Dependencies(taskID int) ([]int, error) {
+	rows, err := s.q.Query("SELECT depends_on_id FROM task_dependencies WHERE task_id = ? ORDER BY depends_on_id", taskID)
+	if err != nil {
+		return nil, fmt.Errorf("query dependencies of task %d: %w", taskID, err)
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("scan dependency: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// AllDependencies returns every dependency as a map from task ID to the
+// IDs it depends on.
+func (s *TaskStore) AllDependencies() (map[int][]int, error) {
+	rows, err := s.q.Query("SELECT task_id, depends_on_id FROM task_dependencies ORDER BY task_id, depends_on_id")
+	if err != nil {
+		return nil, fmt.Errorf("query dependencies: %w", err)
+	}
+	defer rows.Close()
+
+	deps := make(map[int][]int)
+	for rows.Next() {
+		var id, dep int
+		if err := rows.Scan(&id, &dep); err != nil {
+			return nil, fmt.Errorf("scan dependency: %w", err)
+		}
+		deps[id] = append(deps[id], dep)
+	}
+	return deps, rows.Err()
+}
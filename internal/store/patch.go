@@ -0,0 +1,129 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// ErrVersionConflict is returned by UpdateTask when the task was changed
+// since the caller read it.
+var ErrVersionConflict = errors.New("task was modified concurrently")
+
+// ErrParentCycle is returned by SetParent when the new parent is the task
+// itself or one of its sub-tasks.
+var ErrParentCycle = errors.New("parent is the task itself or one of its sub-tasks")
+
+// TaskPatch lists the changes applied by UpdateTask. Nil fields are left
+// untouched; the Clear flags reset an optional field.
+type TaskPatch struct {
+	Title            *string
+	Status           *model.TaskStatus
+	Description      *string
+	ClearDescription bool
+	DueDate          *string
+	ClearDueDate     bool
+	ScheduledOn      *string
+	ClearScheduledOn bool
+	ParentID         *int
+	ClearParent      bool
+	// Tags replaces the task's tags by name, creating missing ones with
+	// TagColor.
+	Tags     *[]string
+	TagColor string
+}
+
+// UpdateTask applies p to a task in one transaction, bumping its version
+// once if anything changed. If version is non-zero
+// it must match the task's current version, otherwise ErrVersionConflict is
+// returned and nothing changes.
+func (s *TaskStore) UpdateTask(id, version int, p TaskPatch) (model.Task, error) {
+	var updated model.Task
+	err := s.WithTx(func(tx *TaskStore) error {
+		cur, err := tx.GetByID(id)
+		if err != nil {
+			return err
+		}
+		if version != 0 && cur.Version != version {
+			return fmt.Errorf("update task %d: %w", id, ErrVersionConflict)
+		}
+
+		if p.Title != nil {
+			if *p.Title == "" {
+				return fmt.Errorf("update task %d: title must not be empty", id)
+			}
+			if err := tx.UpdateTitle(id, *p.Title); err != nil {
+				return err
+			}
+		}
+		if p.Status != nil {
+			if err := tx.UpdateStatus(id, *p.Status); err != nil {
+				return err
+			}
+		}
+		if p.Description != nil || p.ClearDescription {
+			if err := tx.UpdateDescription(id, p.Description); err != nil {
+				return err
+			}
+		}
+		if p.DueDate != nil || p.ClearDueDate {
+			if err := tx.SetDueDate(id, p.DueDate); err != nil {
+				return err
+			}
+		}
+		if p.ScheduledOn != nil || p.ClearScheduledOn {
+			if err := tx.SetScheduledOn(id, p.ScheduledOn); err != nil {
+				return err
+			}
+		}
+		if p.ParentID != nil || p.ClearParent {
+			if err := tx.SetParent(id, p.ParentID); err != nil {
+				return err
+			}
+		}
+		if p.Tags != nil {
+			if err := tx.replaceTags(id, *p.Tags, p.TagColor); err != nil {
+				return err
+			}
+		}
+
+		// Each setter bumps the version; collapse them into a single step so
+		// one patch is one revision.
+		updated, err = tx.GetByID(id)
+		if err != nil || updated.Version <= cur.Version+1 {
+			return err
+		}
+		updated.Version = cur.Version + 1
+		_, err = tx.q.Exec("UPDATE tasks SET version = ? WHERE id = ?", updated.Version, id)
+		return err
+	})
+	return updated, err
+}
+
+// replaceTags makes names the exact tag set of a task.
+func (s *TaskStore) replaceTags(taskID int, names []string, color string) error {
+	current, err := s.TagsForTask(taskID)
+	if err != nil {
+		return err
+	}
+	want := make(map[int]bool)
+	for _, name := range names {
+		tag, err := s.EnsureTag(name, color)
+		if err != nil {
+			return err
+		}
+		want[tag.ID] = true
+		if err := s.AssignTag(taskID, tag.ID); err != nil {
+			return err
+		}
+	}
+	for _, tag := range current {
+		if !want[tag.ID] {
+			if err := s.UnassignTag(taskID, tag.ID); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package store
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// A query is a whitespace-separated list of terms that must all match.
+// Prefixing a term with "-" negates it. Supported terms:
+//
+//	tag:work          task carries the tag or one nested below it,
+//	                  e.g. work/clientA (tag:a,b matches either)
+//	status:open       open, done, todo, doing (or the full status names)
+//	due<=+7d          due, scheduled and created accept : = < <= > >=
+//	                  with YYYY-MM-DD, today, tomorrow, yesterday, none
+//	                  or an offset such as +3d, -2w, +1m
+//	has:description   description, due, scheduled, tags, parent, children
+//	is:overdue        root, overdue, today, open, done
+//	parent:12         direct children of task 12
+//	title:"a b"       title contains the text
+//	word              title or description contains the word
+//
+// Words with an unknown key, such as URLs, are searched for as text.
+var termPattern = regexp.MustCompile(`^(-?)([a-z_]+)(:|<=|>=|<|>|=)(.*)$`)
+
+var offsetPattern = regexp.MustCompile(`^([+-]\d+)([dwm])$`)
+
+// queryKeys are the keys understood before a query operator.
+var queryKeys = []string{"tag", "status", "due", "scheduled", "created", "has", "is", "parent", "title"}
+
+// likeEscaper escapes the LIKE wildcards, for patterns with ESCAPE '\'.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// containsPattern returns a LIKE pattern matching text anywhere.
+func containsPattern(text string) string {
+	return "%" + likeEscaper.Replace(text) + "%"
+}
+
+// ParseQuery compiles a query into a SQL condition over the tasks table
+// together with its positional arguments. An empty query matches everything.
+func ParseQuery(query string) (string, []any, error) {
+	terms, err := splitTerms(query)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(terms) == 0 {
+		return "1", nil, nil
+	}
+
+	var conds []string
+	var args []any
+	for _, term := range terms {
+		cond, termArgs, err := compileTerm(term)
+		if err != nil {
+			return "", nil, err
+		}
+		conds = append(conds, cond)
+		args = append(args, termArgs...)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
+
+// splitTerms splits on whitespace while keeping double-quoted text together.
+func splitTerms(query string) ([]string, error) {
+	var terms []string
+	var cur strings.Builder
+	inQuote := false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if cur.Len() > 0 {
+				terms = append(terms, cur.String())
+				cur.Reset()
+			}
+		default:
+			cur.WriteRune(r)
+		}
+	}
+	if inQuote {
+		return nil, fmt.Errorf("unterminated quote in query")
+	}
+	if cur.Len() > 0 {
+		terms = append(terms, cur.String())
+	}
+	return terms, nil
+}
+
+func compileTerm(term string) (string, []any, error) {
+	m := termPattern.FindStringSubmatch(term)
+	if m == nil || !slices.Contains(queryKeys, m[2]) {
+		negate := strings.HasPrefix(term, "-") && len(term) > 1
+		if negate {
+			term = term[1:]
+		}
+		cond := `(title LIKE ? ESCAPE '\' OR IFNULL(description, '') LIKE ? ESCAPE '\')`
+		like := containsPattern(term)
+		if negate {
+			cond = "NOT " + cond
+		}
+		return cond, []any{like, like}, nil
+	}
+
+	negate, key, op, value := m[1] == "-", m[2], m[3], m[4]
+	if value == "" {
+		return "", nil, fmt.Errorf("missing value in %q", term)
+	}
+
+	var cond string
+	var args []any
+	var err error
+	switch key {
+	case "tag":
+		cond, args = tagCondition(strings.Split(value, ","))
+	case "status":
+		cond, err = statusCondition(value)
+	case "due":
+		cond, args, err = dateCondition("due_date", op, value)
+	case "scheduled":
+		cond, args, err = dateCondition("scheduled_on", op, value)
+	case "created":
+		cond, args, err = dateCondition("date(created_at)", op, value)
+	case "has":
+		cond, err = hasCondition(value)
+	case "is":
+		cond, args, err = isCondition(value)
+	case "parent":
+		id, convErr := strconv.Atoi(value)
+		if convErr != nil {
+			return "", nil, fmt.Errorf("invalid parent id %q", value)
+		}
+		cond, args = "parent_id = ?", []any{id}
+	case "title":
+		cond, args = `title LIKE ? ESCAPE '\'`, []any{containsPattern(value)}
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if key != "due" && key != "scheduled" && key != "created" && op != ":" && op != "=" {
+		return "", nil, fmt.Errorf("operator %q is not supported for %s", op, key)
+	}
+
+	cond = "(" + cond + ")"
+	if negate {
+		// A comparison with a NULL column is NULL, which NOT keeps NULL:
+		// count it as false first, so that -is:today matches unscheduled
+		// tasks.
+		cond = "NOT IFNULL(" + cond + ", 0)"
+	}
+	return cond, args, nil
+}
+
+func tagCondition(names []string) (string, []any) {
+	var conds []string
+	var args []any
+	for _, name := range names {
+		// A tag also matches every tag nested below it.
+		conds = append(conds, "(g.name = ? OR substr(g.name, 1, ?) = ?)")
+		args = append(args, name, utf8.RuneCountInString(name)+1, name+model.TagSeparator)
+	}
+	cond := `EXISTS (SELECT 1 FROM task_tags tt
+		INNER JOIN tags g ON g.id = tt.tag_id
+		WHERE tt.task_id = tasks.id AND (` + strings.Join(conds, " OR ") + `))`
+	return cond, args
+}
+
+func statusCondition(value string) (string, error) {
+	switch value {
+	case "open":
+		return fmt.Sprintf("completed != %d", model.StatusCompleted), nil
+	case "done", "completed":
+		return fmt.Sprintf("completed = %d", model.StatusCompleted), nil
+	case "todo", "not_started":
+		return fmt.Sprintf("completed = %d", model.StatusNotStarted), nil
+	case "doing", "in_progress", "started":
+		return fmt.Sprintf("completed = %d", model.StatusInProgress), nil
+	}
+	return "", fmt.Errorf("unknown status %q", value)
+}
+
+func dateCondition(column, op, value string) (string, []any, error) {
+	if value == "none" {
+		if op != ":" && op != "=" {
+			return "", nil, fmt.Errorf("operator %q cannot be used with none", op)
+		}
+		return column + " IS NULL", nil, nil
+	}
+	date, err := ResolveDate(value)
+	if err != nil {
+		return "", nil, err
+	}
+	if op == ":" {
+		op = "="
+	}
+	return column + " IS NOT NULL AND " + column + " " + op + " ?", []any{date}, nil
+}
+
+// ResolveDate turns a date as accepted in queries (YYYY-MM-DD, today,
+// tomorrow, yesterday or an offset like +3d) into YYYY-MM-DD.
+func ResolveDate(value string) (string, error) {
+	now := time.Now()
+	switch value {
+	case "today":
+		return now.Format("2006-01-02"), nil
+	case "tomorrow":
+		return now.AddDate(0, 0, 1).Format("2006-01-02"), nil
+	case "yesterday":
+		return now.AddDate(0, 0, -1).Format("2006-01-02"), nil
+	}
+	if m := offsetPattern.FindStringSubmatch(value); m != nil {
+		n, _ := strconv.Atoi(m[1])
+		switch m[2] {
+		case "d":
+			return now.AddDate(0, 0, n).Format("2006-01-02"), nil
+		case "w":
+			return now.AddDate(0, 0, 7*n).Format("2006-01-02"), nil
+		case "m":
+			return now.AddDate(0, n, 0).Format("2006-01-02"), nil
+		}
+	}
+	if _, err := time.Parse("2006-01-02", value); err != nil {
+		return "", fmt.Errorf("invalid date %q", value)
+	}
+	return value, nil
+}
+
+func hasCondition(value string) (string, error) {
+	switch value {
+	case "description":
+		return "description IS NOT NULL AND description != ''", nil
+	case "due":
+		return "due_date IS NOT NULL", nil
+	case "scheduled":
+		return "scheduled_on IS NOT NULL", nil
+	case "tags":
+		return "EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = tasks.id)", nil
+	case "parent":
+		return "parent_id IS NOT NULL", nil
+	case "children":
+		return "EXISTS (SELECT 1 FROM tasks c WHERE c.parent_id = tasks.id)", nil
+	}
+	return "", fmt.Errorf("unknown has: value %q", value)
+}
+
+func isCondition(value string) (string, []any, error) {
+	today := time.Now().Format("2006-01-02")
+	switch value {
+	case "root":
+		return "parent_id IS NULL", nil, nil
+	case "overdue":
+		return fmt.Sprintf("due_date IS NOT NULL AND due_date < ? AND completed != %d", model.StatusCompleted), []any{today}, nil
+	case "today":
+		return "scheduled_on = ?", []any{today}, nil
+	case "open", "done":
+		cond, err := statusCondition(value)
+		return cond, nil, err
+	}
+	return "", nil, fmt.Errorf("unknown is: value %q", value)
+}
+
+// Search returns the tasks matching query, ordered by creation date.
+func (s *TaskStore) Search(query string) ([]model.Task, error) {
+	where, args, err := ParseQuery(query)
+	if err != nil {
+		return nil, fmt.Errorf("parse query: %w", err)
+	}
+
+	rows, err := s.q.Query("SELECT "+taskColumns+" FROM tasks WHERE "+where+" ORDER BY created_at ASC", args...)
+	if err != nil {
+		return nil, fmt.Errorf("search tasks: %w", err)
+	}
+	defer rows.Close()
+
+	var tasks []model.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan task: %w", err)
+		}
+		tasks = append(tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := s.loadTagsForTasks(tasks); err != nil {
+		return nil, fmt.Errorf("load tags: %w", err)
+	}
+
+	return tasks, nil
+}
@@ -0,0 +1,74 @@
+package store
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchNegatedNullable(t *testing.T) {
+	s := newTestStore(t)
+	past, err := s.Add("past", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	date := "2020-01-01"
+	if err := s.SetScheduledOn(past.ID, &date); err != nil {
+		t.Fatal(err)
+	}
+	parentID := past.ID
+	if _, err := s.Add("child", &parentID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Add("root", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"-is:today", []string{"past", "child", "root"}},
+		{"-parent:1", []string{"past", "root"}},
+		{"-due:2020-01-01", []string{"past", "child", "root"}},
+		{"-scheduled<today", []string{"child", "root"}},
+		{"-has:due", []string{"past", "child", "root"}},
+	}
+	for _, tt := range tests {
+		if got := searchTitles(t, s, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	s := newTestStore(t)
+	for _, title := range []string{"100% done", "1000 done", "snake_case", "snakecase", "see https://example.com/x"} {
+		if _, err := s.Add(title, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"100%", []string{"100% done"}},
+		{"title:snake_", []string{"snake_case"}},
+		{`-"_"`, []string{"100% done", "1000 done", "snakecase", "see https://example.com/x"}},
+		{"https://example.com/x", []string{"see https://example.com/x"}},
+		{"-https://example.com", []string{"100% done", "1000 done", "snake_case", "snakecase"}},
+	}
+	for _, tt := range tests {
+		if got := searchTitles(t, s, tt.query); !slices.Equal(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	for _, query := range []string{"status:nope", "parent:x", "due:", `title:"open`, "is:later"} {
+		if _, _, err := ParseQuery(query); err == nil {
+			t.Errorf("ParseQuery(%q) succeeded, want an error", query)
+		}
+	}
+}
@@ -2,10 +2,14 @@ package store
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nissyi-gh/flow/internal/model"
 	_ "modernc.org/sqlite"
@@ -14,6 +18,14 @@ import (
 // TaskStore manages SQLite persistence for tasks.
 type TaskStore struct {
 	db *sql.DB
+	q  querier // db, or the open transaction inside WithTx
+}
+
+// querier is the subset of *sql.DB and *sql.Tx used by TaskStore.
+type querier interface {
+	Exec(query string, args ...any) (sql.Result, error)
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
 }
 
 func defaultDBPath() (string, error) {
@@ -42,7 +54,9 @@ func NewTaskStore(dbPath string) (*TaskStore, error) {
 		}
 	}
 
-	db, err := sql.Open("sqlite", dbPath)
+	// foreign_keys is a per-connection setting, so it goes in the DSN for
+	// every connection of the pool to cascade deletes.
+	db, err := sql.Open("sqlite", dbPath+"?_pragma=foreign_keys(1)")
 	if err != nil {
 		return nil, fmt.Errorf("open db: %w", err)
 	}
@@ -52,11 +66,6 @@ func NewTaskStore(dbPath string) (*TaskStore, error) {
 		return nil, fmt.Errorf("set WAL mode: %w", err)
 	}
 
-	if _, err := db.Exec("PRAGMA foreign_keys=ON"); err != nil {
-		db.Close()
-		return nil, fmt.Errorf("enable foreign keys: %w", err)
-	}
-
 	schema := `CREATE TABLE IF NOT EXISTS tasks (
 		id         INTEGER PRIMARY KEY AUTOINCREMENT,
 		title      TEXT    NOT NULL,
@@ -93,7 +102,60 @@ func NewTaskStore(dbPath string) (*TaskStore, error) {
 		return nil, fmt.Errorf("migrate tags: %w", err)
 	}
 
-	return &TaskStore{db: db}, nil
+	if err := migrateUpdatedAt(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate updated_at: %w", err)
+	}
+
+	if err := migrateVersion(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate version: %w", err)
+	}
+
+	if err := migratePriority(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate priority: %w", err)
+	}
+
+	if err := migrateSettings(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate settings: %w", err)
+	}
+
+	if err := migrateViews(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate views: %w", err)
+	}
+
+	if err := migrateDependencies(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("migrate dependencies: %w", err)
+	}
+
+	return &TaskStore{db: db, q: db}, nil
+}
+
+// columnExists reports whether the tasks table has the named column.
+func columnExists(db *sql.DB, column string) (bool, error) {
+	rows, err := db.Query("PRAGMA table_info(tasks)")
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var cid int
+		var name, typ string
+		var notNull, pk int
+		var dfltValue sql.NullString
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dfltValue, &pk); err != nil {
+			return false, err
+		}
+		if name == column {
+			return true, nil
+		}
+	}
+	return false, rows.Err()
 }
 
 func migrateParentID(db *sql.DB) error {
@@ -246,6 +308,86 @@ func migrateTags(db *sql.DB) error {
 	return nil
 }
 
+func migrateUpdatedAt(db *sql.DB) error {
+	exists, err := columnExists(db, "updated_at")
+	if err != nil || exists {
+		return err
+	}
+	// SQLite does not allow a non-constant default on ADD COLUMN, so existing
+	// rows are backfilled from created_at instead.
+	if _, err := db.Exec("ALTER TABLE tasks ADD COLUMN updated_at TEXT"); err != nil {
+		return err
+	}
+	_, err = db.Exec("UPDATE tasks SET updated_at = created_at WHERE updated_at IS NULL")
+	return err
+}
+
+func migrateVersion(db *sql.DB) error {
+	exists, err := columnExists(db, "version")
+	if err != nil || exists {
+		return err
+	}
+	_, err = db.Exec("ALTER TABLE tasks ADD COLUMN version INTEGER NOT NULL DEFAULT 1")
+	return err
+}
+
+func migratePriority(db *sql.DB) error {
+	exists, err := columnExists(db, "priority")
+	if err != nil || exists {
+		return err
+	}
+	_, err = db.Exec("ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0")
+	return err
+}
+
+func migrateSettings(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS settings (
+		key   TEXT PRIMARY KEY,
+		value TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create settings table: %w", err)
+	}
+	return nil
+}
+
+func migrateViews(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS views (
+		id    INTEGER PRIMARY KEY AUTOINCREMENT,
+		name  TEXT NOT NULL UNIQUE,
+		query TEXT NOT NULL
+	)`)
+	if err != nil {
+		return fmt.Errorf("create views table: %w", err)
+	}
+	return nil
+}
+
+func migrateDependencies(db *sql.DB) error {
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS task_dependencies (
+		task_id       INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		depends_on_id INTEGER NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+		PRIMARY KEY (task_id, depends_on_id)
+	)`)
+	if err != nil {
+		return fmt.Errorf("create task_dependencies table: %w", err)
+	}
+	// Deletes on connections without foreign keys could leave links to
+	// deleted tasks behind.
+	_, err = db.Exec(`DELETE FROM task_dependencies
+		WHERE task_id NOT IN (SELECT id FROM tasks) OR depends_on_id NOT IN (SELECT id FROM tasks)`)
+	if err != nil {
+		return fmt.Errorf("remove dangling dependencies: %w", err)
+	}
+	return nil
+}
+
+// defaultTagColor matches the default of the tags.color column.
+const defaultTagColor = "39"
+
+// taskColumns is the column list expected by scanTask.
+const taskColumns = "id, title, completed, created_at, parent_id, scheduled_on, due_date, description, updated_at, version, priority"
+
 func scanTask(scanner interface{ Scan(...any) error }) (model.Task, error) {
 	var t model.Task
 	var comp int
@@ -254,12 +396,18 @@ func scanTask(scanner interface{ Scan(...any) error }) (model.Task, error) {
 	var scheduledOn sql.NullString
 	var dueDate sql.NullString
 	var description sql.NullString
-	if err := scanner.Scan(&t.ID, &t.Title, &comp, &createdStr, &parentID, &scheduledOn, &dueDate, &description); err != nil {
+	var updatedStr sql.NullString
+	if err := scanner.Scan(&t.ID, &t.Title, &comp, &createdStr, &parentID, &scheduledOn, &dueDate, &description, &updatedStr, &t.Version, &t.Priority); err != nil {
 		return model.Task{}, err
 	}
 	t.Status = model.TaskStatus(comp)
 	t.Completed = t.Status == model.StatusCompleted
 	t.CreatedAt, _ = time.Parse("2006-01-02 15:04:05", createdStr)
+	if updatedStr.Valid {
+		t.UpdatedAt, _ = time.Parse("2006-01-02 15:04:05", updatedStr.String)
+	} else {
+		t.UpdatedAt = t.CreatedAt
+	}
 	if parentID.Valid {
 		pid := int(parentID.Int64)
 		t.ParentID = &pid
@@ -284,9 +432,9 @@ func (s *TaskStore) Add(title string, parentID *int) (model.Task, error) {
 	var res sql.Result
 	var err error
 	if parentID != nil {
-		res, err = s.db.Exec("INSERT INTO tasks (title, parent_id) VALUES (?, ?)", title, *parentID)
+		res, err = s.q.Exec("INSERT INTO tasks (title, parent_id, updated_at) VALUES (?, ?, datetime('now'))", title, *parentID)
 	} else {
-		res, err = s.db.Exec("INSERT INTO tasks (title) VALUES (?)", title)
+		res, err = s.q.Exec("INSERT INTO tasks (title, updated_at) VALUES (?, datetime('now'))", title)
 	}
 	if err != nil {
 		return model.Task{}, fmt.Errorf("insert task: %w", err)
@@ -295,9 +443,39 @@ func (s *TaskStore) Add(title string, parentID *int) (model.Task, error) {
 	return s.GetByID(int(id))
 }
 
+// Restore inserts a task with every field taken from t, including its
+// timestamps and the tags in t.Tags (by ID), and returns it with the newly
+// assigned ID. t.ID is ignored; t.ParentID must refer to an existing task.
+func (s *TaskStore) Restore(t model.Task) (model.Task, error) {
+	const layout = "2006-01-02 15:04:05"
+	created, updated := t.CreatedAt, t.UpdatedAt
+	if created.IsZero() {
+		created = time.Now()
+	}
+	if updated.IsZero() {
+		updated = created
+	}
+	res, err := s.q.Exec(
+		`INSERT INTO tasks (title, completed, created_at, parent_id, scheduled_on, due_date, description, updated_at, priority)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		t.Title, int(t.Status), created.UTC().Format(layout), t.ParentID,
+		t.ScheduledOn, t.DueDate, t.Description, updated.UTC().Format(layout), t.Priority,
+	)
+	if err != nil {
+		return model.Task{}, fmt.Errorf("restore task %q: %w", t.Title, err)
+	}
+	id, _ := res.LastInsertId()
+	for _, tag := range t.Tags {
+		if _, err := s.q.Exec("INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)", id, tag.ID); err != nil {
+			return model.Task{}, fmt.Errorf("restore tags of task %q: %w", t.Title, err)
+		}
+	}
+	return s.GetByID(int(id))
+}
+
 // List returns all tasks ordered by creation date ascending.
 func (s *TaskStore) List() ([]model.Task, error) {
-	rows, err := s.db.Query("SELECT id, title, completed, created_at, parent_id, scheduled_on, due_date, description FROM tasks ORDER BY created_at ASC")
+	rows, err := s.q.Query("SELECT " + taskColumns + " FROM tasks ORDER BY created_at ASC")
 	if err != nil {
 		return nil, fmt.Errorf("query tasks: %w", err)
 	}
@@ -324,7 +502,7 @@ func (s *TaskStore) List() ([]model.Task, error) {
 
 // GetByID retrieves a single task by its ID.
 func (s *TaskStore) GetByID(id int) (model.Task, error) {
-	row := s.db.QueryRow("SELECT id, title, completed, created_at, parent_id, scheduled_on, due_date, description FROM tasks WHERE id = ?", id)
+	row := s.q.QueryRow("SELECT "+taskColumns+" FROM tasks WHERE id = ?", id)
 	t, err := scanTask(row)
 	if err != nil {
 		return model.Task{}, fmt.Errorf("get task %d: %w", id, err)
@@ -340,8 +518,8 @@ func (s *TaskStore) GetByID(id int) (model.Task, error) {
 // SetStatus sets the task status to the given value.
 // Passing the current status resets to 0 (not started).
 func (s *TaskStore) SetStatus(id int, status model.TaskStatus) error {
-	_, err := s.db.Exec(
-		"UPDATE tasks SET completed = CASE WHEN completed = ? THEN 0 ELSE ? END WHERE id = ?",
+	_, err := s.q.Exec(
+		"UPDATE tasks SET completed = CASE WHEN completed = ? THEN 0 ELSE ? END, updated_at = datetime('now'), version = version + 1 WHERE id = ?",
 		status, status, id,
 	)
 	if err != nil {
@@ -350,12 +528,22 @@ func (s *TaskStore) SetStatus(id int, status model.TaskStatus) error {
 	return nil
 }
 
+// UpdateStatus sets the task status unconditionally, unlike SetStatus which
+// toggles back to not started when the status is already set.
+func (s *TaskStore) UpdateStatus(id int, status model.TaskStatus) error {
+	_, err := s.q.Exec("UPDATE tasks SET completed = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", status, id)
+	if err != nil {
+		return fmt.Errorf("update status task %d: %w", id, err)
+	}
+	return nil
+}
+
 // ToggleToday toggles the scheduled_on date for today.
 // If scheduled_on is already today, it clears it; otherwise sets it to today.
 func (s *TaskStore) ToggleToday(id int) error {
 	today := time.Now().Format("2006-01-02")
-	_, err := s.db.Exec(
-		"UPDATE tasks SET scheduled_on = CASE WHEN scheduled_on = ? THEN NULL ELSE ? END WHERE id = ?",
+	_, err := s.q.Exec(
+		"UPDATE tasks SET scheduled_on = CASE WHEN scheduled_on = ? THEN NULL ELSE ? END, updated_at = datetime('now'), version = version + 1 WHERE id = ?",
 		today, today, id,
 	)
 	if err != nil {
@@ -364,14 +552,78 @@ func (s *TaskStore) ToggleToday(id int) error {
 	return nil
 }
 
+// SetScheduledOn sets or clears the scheduled date for a task.
+// Pass nil to clear it.
+func (s *TaskStore) SetScheduledOn(id int, date *string) error {
+	var err error
+	if date != nil {
+		_, err = s.q.Exec("UPDATE tasks SET scheduled_on = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", *date, id)
+	} else {
+		_, err = s.q.Exec("UPDATE tasks SET scheduled_on = NULL, updated_at = datetime('now'), version = version + 1 WHERE id = ?", id)
+	}
+	if err != nil {
+		return fmt.Errorf("set scheduled date task %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetParent moves a task under a new parent. Pass nil to make it a root task.
+// Moving a task below itself or one of its descendants is rejected.
+func (s *TaskStore) SetParent(id int, parentID *int) error {
+	if parentID == nil {
+		_, err := s.q.Exec("UPDATE tasks SET parent_id = NULL, updated_at = datetime('now'), version = version + 1 WHERE id = ?", id)
+		if err != nil {
+			return fmt.Errorf("set parent of task %d: %w", id, err)
+		}
+		return nil
+	}
+
+	for cur := parentID; cur != nil; {
+		if *cur == id {
+			return fmt.Errorf("set parent of task %d: %w", id, ErrParentCycle)
+		}
+		t, err := s.GetByID(*cur)
+		if err != nil {
+			return fmt.Errorf("set parent of task %d: %w", id, err)
+		}
+		cur = t.ParentID
+	}
+	_, err := s.q.Exec("UPDATE tasks SET parent_id = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", *parentID, id)
+	if err != nil {
+		return fmt.Errorf("set parent of task %d: %w", id, err)
+	}
+	return nil
+}
+
+// SetPriority sets a task's priority: 0 for none, 1 (A) to 26 (Z).
+func (s *TaskStore) SetPriority(id, priority int) error {
+	if priority < 0 || priority > 26 {
+		return fmt.Errorf("set priority of task %d: priority %d out of range", id, priority)
+	}
+	_, err := s.q.Exec("UPDATE tasks SET priority = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", priority, id)
+	if err != nil {
+		return fmt.Errorf("set priority of task %d: %w", id, err)
+	}
+	return nil
+}
+
+// UpdateTitle renames a task.
+func (s *TaskStore) UpdateTitle(id int, title string) error {
+	_, err := s.q.Exec("UPDATE tasks SET title = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", title, id)
+	if err != nil {
+		return fmt.Errorf("update title for task %d: %w", id, err)
+	}
+	return nil
+}
+
 // SetDueDate sets or clears the due date for a task.
 // Pass nil to clear the due date.
 func (s *TaskStore) SetDueDate(id int, dueDate *string) error {
 	var err error
 	if dueDate != nil {
-		_, err = s.db.Exec("UPDATE tasks SET due_date = ? WHERE id = ?", *dueDate, id)
+		_, err = s.q.Exec("UPDATE tasks SET due_date = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", *dueDate, id)
 	} else {
-		_, err = s.db.Exec("UPDATE tasks SET due_date = NULL WHERE id = ?", id)
+		_, err = s.q.Exec("UPDATE tasks SET due_date = NULL, updated_at = datetime('now'), version = version + 1 WHERE id = ?", id)
 	}
 	if err != nil {
 		return fmt.Errorf("set due date task %d: %w", id, err)
@@ -381,7 +633,7 @@ func (s *TaskStore) SetDueDate(id int, dueDate *string) error {
 
 // Delete removes a task by ID. Child tasks are cascade-deleted.
 func (s *TaskStore) Delete(id int) error {
-	_, err := s.db.Exec("DELETE FROM tasks WHERE id = ?", id)
+	_, err := s.q.Exec("DELETE FROM tasks WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete task %d: %w", id, err)
 	}
@@ -391,7 +643,7 @@ func (s *TaskStore) Delete(id int) error {
 // HasChildren checks if a task has any child tasks.
 func (s *TaskStore) HasChildren(id int) (bool, error) {
 	var count int
-	err := s.db.QueryRow("SELECT COUNT(*) FROM tasks WHERE parent_id = ?", id).Scan(&count)
+	err := s.q.QueryRow("SELECT COUNT(*) FROM tasks WHERE parent_id = ?", id).Scan(&count)
 	if err != nil {
 		return false, fmt.Errorf("check children of task %d: %w", id, err)
 	}
@@ -402,9 +654,9 @@ func (s *TaskStore) HasChildren(id int) (bool, error) {
 func (s *TaskStore) UpdateDescription(id int, description *string) error {
 	var err error
 	if description != nil {
-		_, err = s.db.Exec("UPDATE tasks SET description = ? WHERE id = ?", *description, id)
+		_, err = s.q.Exec("UPDATE tasks SET description = ?, updated_at = datetime('now'), version = version + 1 WHERE id = ?", *description, id)
 	} else {
-		_, err = s.db.Exec("UPDATE tasks SET description = NULL WHERE id = ?", id)
+		_, err = s.q.Exec("UPDATE tasks SET description = NULL, updated_at = datetime('now'), version = version + 1 WHERE id = ?", id)
 	}
 	if err != nil {
 		return fmt.Errorf("update description for task %d: %w", id, err)
@@ -418,7 +670,7 @@ func (s *TaskStore) loadTagsForTasks(tasks []model.Task) error {
 		return nil
 	}
 
-	rows, err := s.db.Query(
+	rows, err := s.q.Query(
 		`SELECT tt.task_id, t.id, t.name, t.color
 		 FROM task_tags tt
 		 INNER JOIN tags t ON t.id = tt.tag_id
@@ -440,6 +692,16 @@ func (s *TaskStore) loadTagsForTasks(tasks []model.Task) error {
 	if err := rows.Err(); err != nil {
 		return err
 	}
+	rows.Close()
+
+	colors, err := s.tagColorIndex()
+	if err != nil {
+		return err
+	}
+	for _, tags := range tagMap {
+		resolveTagColors(tags, colors)
+		sortTagsByPath(tags)
+	}
 
 	for i := range tasks {
 		if tags, ok := tagMap[tasks[i].ID]; ok {
@@ -449,19 +711,167 @@ func (s *TaskStore) loadTagsForTasks(tasks []model.Task) error {
 	return nil
 }
 
-// CreateTag inserts a new tag and returns it.
+// CreateTag inserts a new tag and returns it. An empty color makes the tag
+// inherit the color of its parent tag.
 func (s *TaskStore) CreateTag(name string, color string) (model.Tag, error) {
-	res, err := s.db.Exec("INSERT INTO tags (name, color) VALUES (?, ?)", name, color)
+	segments, err := tagSegments(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+	name = strings.Join(segments, model.TagSeparator)
+	res, err := s.q.Exec("INSERT INTO tags (name, color) VALUES (?, ?)", name, color)
 	if err != nil {
 		return model.Tag{}, fmt.Errorf("insert tag: %w", err)
 	}
 	id, _ := res.LastInsertId()
-	return model.Tag{ID: int(id), Name: name, Color: color}, nil
+	tag := model.Tag{ID: int(id), Name: name, Color: color}
+	if color == "" {
+		colors, err := s.tagColorIndex()
+		if err != nil {
+			return model.Tag{}, err
+		}
+		resolveTagColor(&tag, colors)
+	}
+	return tag, nil
+}
+
+// ErrInvalidTagName is returned for tag names that are empty or have an
+// empty segment, such as "a//b" or "x/".
+var ErrInvalidTagName = errors.New("invalid tag name")
+
+// tagSegments splits a hierarchical tag name into its trimmed segments.
+func tagSegments(name string) ([]string, error) {
+	segments := strings.Split(name, model.TagSeparator)
+	for i, seg := range segments {
+		segments[i] = strings.TrimSpace(seg)
+		if segments[i] == "" {
+			return nil, fmt.Errorf("%w %q", ErrInvalidTagName, name)
+		}
+	}
+	return segments, nil
+}
+
+// EnsureTag returns the tag with the given hierarchical name, creating it and
+// any missing parent tags. A newly created top-level tag gets color; nested
+// tags inherit their color from the parent.
+func (s *TaskStore) EnsureTag(name string, color string) (model.Tag, error) {
+	segments, err := tagSegments(name)
+	if err != nil {
+		return model.Tag{}, err
+	}
+
+	var tag model.Tag
+	err = s.WithTx(func(tx *TaskStore) error {
+		for i := range segments {
+			path := strings.Join(segments[:i+1], model.TagSeparator)
+			var id int
+			err := tx.q.QueryRow("SELECT id FROM tags WHERE name = ?", path).Scan(&id)
+			if err == nil {
+				tag.ID, tag.Name = id, path
+				continue
+			}
+			if err != sql.ErrNoRows {
+				return fmt.Errorf("look up tag %q: %w", path, err)
+			}
+			c := ""
+			if i == 0 {
+				c = color
+			}
+			if tag, err = tx.CreateTag(path, c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return model.Tag{}, err
+	}
+	return s.TagByID(tag.ID)
+}
+
+// TagByName returns the tag with exactly the given name.
+func (s *TaskStore) TagByName(name string) (model.Tag, error) {
+	var id int
+	if err := s.q.QueryRow("SELECT id FROM tags WHERE name = ?", name).Scan(&id); err != nil {
+		return model.Tag{}, fmt.Errorf("get tag %q: %w", name, err)
+	}
+	return s.TagByID(id)
+}
+
+// TagByID returns a tag with its resolved color.
+func (s *TaskStore) TagByID(id int) (model.Tag, error) {
+	var t model.Tag
+	err := s.q.QueryRow("SELECT id, name, color FROM tags WHERE id = ?", id).Scan(&t.ID, &t.Name, &t.Color)
+	if err != nil {
+		return model.Tag{}, fmt.Errorf("get tag %d: %w", id, err)
+	}
+	colors, err := s.tagColorIndex()
+	if err != nil {
+		return model.Tag{}, err
+	}
+	resolveTagColor(&t, colors)
+	return t, nil
+}
+
+// tagColorIndex maps every tag name to its own, possibly empty, color.
+func (s *TaskStore) tagColorIndex() (map[string]string, error) {
+	rows, err := s.q.Query("SELECT name, color FROM tags")
+	if err != nil {
+		return nil, fmt.Errorf("query tag colors: %w", err)
+	}
+	defer rows.Close()
+
+	colors := make(map[string]string)
+	for rows.Next() {
+		var name, color string
+		if err := rows.Scan(&name, &color); err != nil {
+			return nil, fmt.Errorf("scan tag color: %w", err)
+		}
+		colors[name] = color
+	}
+	return colors, rows.Err()
+}
+
+// resolveTagColor gives a tag without its own color the color of its nearest
+// colored ancestor, falling back to the schema default.
+func resolveTagColor(tag *model.Tag, colors map[string]string) {
+	if tag.Color != "" {
+		return
+	}
+	tag.Inherited = true
+	for name := model.ParentTagName(tag.Name); name != ""; name = model.ParentTagName(name) {
+		if c := colors[name]; c != "" {
+			tag.Color = c
+			return
+		}
+	}
+	tag.Color = defaultTagColor
+}
+
+func resolveTagColors(tags []model.Tag, colors map[string]string) {
+	for i := range tags {
+		resolveTagColor(&tags[i], colors)
+	}
+}
+
+// sortTagsByPath orders tags so that every tag directly follows its parent,
+// comparing names segment by segment.
+func sortTagsByPath(tags []model.Tag) {
+	sort.SliceStable(tags, func(i, j int) bool {
+		a := strings.Split(tags[i].Name, model.TagSeparator)
+		b := strings.Split(tags[j].Name, model.TagSeparator)
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return len(a) < len(b)
+	})
 }
 
 // ListTags returns all tags ordered by name.
 func (s *TaskStore) ListTags() ([]model.Tag, error) {
-	rows, err := s.db.Query("SELECT id, name, color FROM tags ORDER BY name ASC")
+	rows, err := s.q.Query("SELECT id, name, color FROM tags ORDER BY name ASC")
 	if err != nil {
 		return nil, fmt.Errorf("query tags: %w", err)
 	}
@@ -475,39 +885,148 @@ func (s *TaskStore) ListTags() ([]model.Tag, error) {
 		}
 		tags = append(tags, t)
 	}
-	return tags, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	colors, err := s.tagColorIndex()
+	if err != nil {
+		return nil, err
+	}
+	resolveTagColors(tags, colors)
+	sortTagsByPath(tags)
+	return tags, nil
 }
 
 // DeleteTag removes a tag by ID. Associated task_tags rows cascade-delete.
 func (s *TaskStore) DeleteTag(id int) error {
-	_, err := s.db.Exec("DELETE FROM tags WHERE id = ?", id)
+	_, err := s.q.Exec("DELETE FROM tags WHERE id = ?", id)
 	if err != nil {
 		return fmt.Errorf("delete tag %d: %w", id, err)
 	}
 	return nil
 }
 
+// RenameTag changes the name of a tag. Nested tags are moved along with it,
+// and any missing parent tags of the new name are created. A tag cannot be
+// moved below itself.
+func (s *TaskStore) RenameTag(id int, name string) error {
+	segments, err := tagSegments(name)
+	if err != nil {
+		return err
+	}
+	name = strings.Join(segments, model.TagSeparator)
+	return s.WithTx(func(tx *TaskStore) error {
+		var old string
+		if err := tx.q.QueryRow("SELECT name FROM tags WHERE id = ?", id).Scan(&old); err != nil {
+			return fmt.Errorf("rename tag %d: %w", id, err)
+		}
+		if strings.HasPrefix(name, old+model.TagSeparator) {
+			return fmt.Errorf("rename tag %q: cannot move a tag below itself", old)
+		}
+		// Nested tags first, so that the renamed tag itself is not matched
+		// as one of them.
+		_, err := tx.q.Exec(
+			`UPDATE tags SET name = ? || substr(name, ?) WHERE substr(name, 1, ?) = ? AND id != ?`,
+			name+model.TagSeparator, utf8.RuneCountInString(old)+2, utf8.RuneCountInString(old)+1, old+model.TagSeparator, id,
+		)
+		if err != nil {
+			return fmt.Errorf("rename tags below %q: %w", old, err)
+		}
+		if _, err := tx.q.Exec("UPDATE tags SET name = ? WHERE id = ?", name, id); err != nil {
+			return fmt.Errorf("rename tag %d: %w", id, err)
+		}
+		if parent := model.ParentTagName(name); parent != "" {
+			if _, err := tx.EnsureTag(parent, defaultTagColor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// SetTagColor changes the lipgloss color code of a tag. An empty color makes
+// the tag inherit its parent's color again.
+func (s *TaskStore) SetTagColor(id int, color string) error {
+	_, err := s.q.Exec("UPDATE tags SET color = ? WHERE id = ?", color, id)
+	if err != nil {
+		return fmt.Errorf("set color of tag %d: %w", id, err)
+	}
+	return nil
+}
+
+// MergeTags moves every assignment of tag srcID onto dstID and deletes srcID.
+func (s *TaskStore) MergeTags(srcID, dstID int) error {
+	if srcID == dstID {
+		return fmt.Errorf("merge tag %d: cannot merge a tag into itself", srcID)
+	}
+	return s.WithTx(func(tx *TaskStore) error {
+		_, err := tx.q.Exec(
+			"INSERT OR IGNORE INTO task_tags (task_id, tag_id) SELECT task_id, ? FROM task_tags WHERE tag_id = ?",
+			dstID, srcID,
+		)
+		if err != nil {
+			return fmt.Errorf("merge tag %d into %d: %w", srcID, dstID, err)
+		}
+		return tx.DeleteTag(srcID)
+	})
+}
+
+// TagUsage returns the number of tasks carrying each tag, keyed by tag ID.
+// Unused tags are absent from the map.
+func (s *TaskStore) TagUsage() (map[int]int, error) {
+	rows, err := s.q.Query("SELECT tag_id, COUNT(*) FROM task_tags GROUP BY tag_id")
+	if err != nil {
+		return nil, fmt.Errorf("query tag usage: %w", err)
+	}
+	defer rows.Close()
+
+	usage := make(map[int]int)
+	for rows.Next() {
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, fmt.Errorf("scan tag usage: %w", err)
+		}
+		usage[id] = count
+	}
+	return usage, rows.Err()
+}
+
 // AssignTag links a tag to a task. Silently succeeds if already assigned.
 func (s *TaskStore) AssignTag(taskID, tagID int) error {
-	_, err := s.db.Exec("INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, tagID)
+	res, err := s.q.Exec("INSERT OR IGNORE INTO task_tags (task_id, tag_id) VALUES (?, ?)", taskID, tagID)
 	if err != nil {
 		return fmt.Errorf("assign tag %d to task %d: %w", tagID, taskID, err)
 	}
-	return nil
+	return s.touchIfChanged(taskID, res)
 }
 
 // UnassignTag removes a tag from a task.
 func (s *TaskStore) UnassignTag(taskID, tagID int) error {
-	_, err := s.db.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?", taskID, tagID)
+	res, err := s.q.Exec("DELETE FROM task_tags WHERE task_id = ? AND tag_id = ?", taskID, tagID)
 	if err != nil {
 		return fmt.Errorf("unassign tag %d from task %d: %w", tagID, taskID, err)
 	}
+	return s.touchIfChanged(taskID, res)
+}
+
+// touchIfChanged bumps the task's updated_at and version when res changed
+// any rows, so tag edits count as task updates.
+func (s *TaskStore) touchIfChanged(taskID int, res sql.Result) error {
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	_, err := s.q.Exec("UPDATE tasks SET updated_at = datetime('now'), version = version + 1 WHERE id = ?", taskID)
+	if err != nil {
+		return fmt.Errorf("touch task %d: %w", taskID, err)
+	}
 	return nil
 }
 
 // TagsForTask returns all tags assigned to a specific task.
 func (s *TaskStore) TagsForTask(taskID int) ([]model.Tag, error) {
-	rows, err := s.db.Query(
+	rows, err := s.q.Query(
 		`SELECT t.id, t.name, t.color FROM tags t
 		 INNER JOIN task_tags tt ON t.id = tt.tag_id
 		 WHERE tt.task_id = ?
@@ -525,13 +1044,24 @@ func (s *TaskStore) TagsForTask(taskID int) ([]model.Tag, error) {
 		}
 		tags = append(tags, t)
 	}
-	return tags, rows.Err()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	rows.Close()
+
+	colors, err := s.tagColorIndex()
+	if err != nil {
+		return nil, err
+	}
+	resolveTagColors(tags, colors)
+	sortTagsByPath(tags)
+	return tags, nil
 }
 
 // ChildrenOf returns the direct child tasks of a given parent task.
 func (s *TaskStore) ChildrenOf(parentID int) ([]model.Task, error) {
-	rows, err := s.db.Query(
-		"SELECT id, title, completed, created_at, parent_id, scheduled_on, due_date, description FROM tasks WHERE parent_id = ? ORDER BY created_at ASC",
+	rows, err := s.q.Query(
+		"SELECT "+taskColumns+" FROM tasks WHERE parent_id = ? ORDER BY created_at ASC",
 		parentID,
 	)
 	if err != nil {
@@ -558,6 +1088,101 @@ func (s *TaskStore) ChildrenOf(parentID int) ([]model.Task, error) {
 	return tasks, nil
 }
 
+// Setting returns the stored value for key, or "" if it has never been set.
+func (s *TaskStore) Setting(key string) (string, error) {
+	var value string
+	err := s.q.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("get setting %q: %w", key, err)
+	}
+	return value, nil
+}
+
+// SetSetting stores value under key, replacing any previous value.
+func (s *TaskStore) SetSetting(key, value string) error {
+	_, err := s.q.Exec(
+		"INSERT INTO settings (key, value) VALUES (?, ?) ON CONFLICT(key) DO UPDATE SET value = excluded.value",
+		key, value,
+	)
+	if err != nil {
+		return fmt.Errorf("set setting %q: %w", key, err)
+	}
+	return nil
+}
+
+// ListViews returns all saved views ordered by name.
+func (s *TaskStore) ListViews() ([]model.View, error) {
+	rows, err := s.q.Query("SELECT id, name, query FROM views ORDER BY name ASC")
+	if err != nil {
+		return nil, fmt.Errorf("query views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []model.View
+	for rows.Next() {
+		var v model.View
+		if err := rows.Scan(&v.ID, &v.Name, &v.Query); err != nil {
+			return nil, fmt.Errorf("scan view: %w", err)
+		}
+		views = append(views, v)
+	}
+	return views, rows.Err()
+}
+
+// SaveView stores a named query. Saving an existing name replaces its query.
+func (s *TaskStore) SaveView(name, query string) (model.View, error) {
+	if _, _, err := ParseQuery(query); err != nil {
+		return model.View{}, fmt.Errorf("save view %q: %w", name, err)
+	}
+	_, err := s.q.Exec(
+		"INSERT INTO views (name, query) VALUES (?, ?) ON CONFLICT(name) DO UPDATE SET query = excluded.query",
+		name, query,
+	)
+	if err != nil {
+		return model.View{}, fmt.Errorf("save view %q: %w", name, err)
+	}
+	v := model.View{Name: name, Query: query}
+	if err := s.q.QueryRow("SELECT id FROM views WHERE name = ?", name).Scan(&v.ID); err != nil {
+		return model.View{}, fmt.Errorf("get view %q: %w", name, err)
+	}
+	return v, nil
+}
+
+// DeleteView removes a saved view by ID.
+func (s *TaskStore) DeleteView(id int) error {
+	_, err := s.q.Exec("DELETE FROM views WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete view %d: %w", id, err)
+	}
+	return nil
+}
+
+// WithTx runs fn inside a single transaction. The store passed to fn is bound
+// to the transaction; if fn returns an error or panics every change is
+// rolled back. Calling WithTx on a store that is already in a transaction
+// just runs fn.
+func (s *TaskStore) WithTx(fn func(tx *TaskStore) error) error {
+	if _, ok := s.q.(*sql.Tx); ok {
+		return fn(s)
+	}
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	// Releases the connection if fn panics; a no-op after Commit.
+	defer tx.Rollback()
+	if err := fn(&TaskStore{db: s.db, q: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
 // Close closes the database connection.
 func (s *TaskStore) Close() error {
 	return s.db.Close()
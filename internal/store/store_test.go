@@ -0,0 +1,171 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newTestStore opens a store in a temporary directory.
+func newTestStore(t *testing.T) *TaskStore {
+	t.Helper()
+	s, err := NewTaskStore(filepath.Join(t.TempDir(), "flow.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+// searchTitles returns the titles of the tasks matching query.
+func searchTitles(t *testing.T, s *TaskStore, query string) []string {
+	t.Helper()
+	tasks, err := s.Search(query)
+	if err != nil {
+		t.Fatalf("Search(%q): %v", query, err)
+	}
+	titles := []string{}
+	for _, task := range tasks {
+		titles = append(titles, task.Title)
+	}
+	return titles
+}
+
+// tagNames returns the names of all tags.
+func tagNames(t *testing.T, s *TaskStore) []string {
+	t.Helper()
+	tags, err := s.ListTags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := []string{}
+	for _, tag := range tags {
+		names = append(names, tag.Name)
+	}
+	return names
+}
+
+func TestRenameTag(t *testing.T) {
+	s := newTestStore(t)
+	work, err := s.EnsureTag("work/clientA", "39")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parent, err := s.TagByName("work")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.RenameTag(parent.ID, "job"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tagNames(t, s), []string{"job", "job/clientA"}; !slices.Equal(got, want) {
+		t.Errorf("after renaming work to job: tags = %v, want %v", got, want)
+	}
+
+	if err := s.RenameTag(work.ID, "clients/a"); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tagNames(t, s), []string{"clients", "clients/a", "job"}; !slices.Equal(got, want) {
+		t.Errorf("after moving job/clientA: tags = %v, want %v", got, want)
+	}
+
+	for _, name := range []string{"job/sub", "", " ", "a//b", "x/", "/child"} {
+		if err := s.RenameTag(parent.ID, name); err == nil {
+			t.Errorf("renaming job to %q succeeded, want an error", name)
+		}
+	}
+	if got, want := tagNames(t, s), []string{"clients", "clients/a", "job"}; !slices.Equal(got, want) {
+		t.Errorf("after the rejected renames: tags = %v, want %v", got, want)
+	}
+
+	if err := s.RenameTag(parent.ID, " team / ops "); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := tagNames(t, s), []string{"clients", "clients/a", "team", "team/ops"}; !slices.Equal(got, want) {
+		t.Errorf("after renaming job to \" team / ops \": tags = %v, want %v", got, want)
+	}
+}
+
+func TestCreateTagName(t *testing.T) {
+	s := newTestStore(t)
+	for _, name := range []string{"", "a//b", "x/", "/child"} {
+		if _, err := s.CreateTag(name, "39"); !errors.Is(err, ErrInvalidTagName) {
+			t.Errorf("CreateTag(%q) = %v, want ErrInvalidTagName", name, err)
+		}
+	}
+	if got := tagNames(t, s); len(got) != 0 {
+		t.Errorf("tags = %v, want none", got)
+	}
+}
+
+func TestWithTxPanic(t *testing.T) {
+	s := newTestStore(t)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("WithTx did not pass on the panic")
+			}
+		}()
+		s.WithTx(func(tx *TaskStore) error {
+			if _, err := tx.Add("rolled back", nil); err != nil {
+				t.Fatal(err)
+			}
+			panic("boom")
+		})
+	}()
+
+	// The rolled back transaction must not keep the database locked.
+	done := make(chan error, 1)
+	go func() {
+		_, err := s.Add("after", nil)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Add blocked after a panic in WithTx")
+	}
+	if got, want := searchTitles(t, s, ""), []string{"after"}; !slices.Equal(got, want) {
+		t.Errorf("tasks = %v, want %v", got, want)
+	}
+}
+
+func TestDeleteCascadesOnEveryConnection(t *testing.T) {
+	s := newTestStore(t)
+	a, err := s.Add("a", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := s.Add("b", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.AddDependency(a.ID, b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Holding the idle connection makes Delete run on a new one.
+	conn, err := s.db.Conn(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := s.Delete(b.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	deps, err := s.Dependencies(a.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(deps) != 0 {
+		t.Errorf("Dependencies(a) = %v after deleting b, want none", deps)
+	}
+}
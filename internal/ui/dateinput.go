@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -13,10 +14,11 @@ import (
 type dateInput struct {
 	fields [3]textinput.Model // 0:YYYY, 1:MM, 2:DD
 	focus  int                // 現在フォーカス中のフィールドインデックス
+	text   *catalog
 }
 
-func newDateInput() dateInput {
-	placeholders := [3]string{"YYYY", "MM", "DD"}
+func newDateInput(text *catalog) dateInput {
+	placeholders := text.DatePlaceholders
 	charLimits := [3]int{4, 2, 2}
 
 	var fields [3]textinput.Model
@@ -36,7 +38,7 @@ func newDateInput() dateInput {
 		fields[i] = ti
 	}
 
-	return dateInput{fields: fields}
+	return dateInput{fields: fields, text: text}
 }
 
 func (d *dateInput) Focus() {
@@ -77,13 +79,13 @@ func (d *dateInput) Value() (string, error) {
 		mm = fmt.Sprintf("%02d", int(now.Month()))
 	}
 	if dd == "" {
-		return "", fmt.Errorf("day is required")
+		return "", errors.New(d.text.DayRequired)
 	}
 
 	dateStr := fmt.Sprintf("%s-%s-%s", yyyy, padLeft(mm, 2), padLeft(dd, 2))
 
 	if _, err := time.Parse("2006-01-02", dateStr); err != nil {
-		return "", fmt.Errorf("invalid date: %s", dateStr)
+		return "", fmt.Errorf(d.text.InvalidDatef, dateStr)
 	}
 
 	return dateStr, nil
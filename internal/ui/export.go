@@ -0,0 +1,163 @@
+package ui
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nissyi-gh/flow/internal/importer"
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// exportOption is an entry of the report export menu.
+type exportOption struct {
+	format  string
+	ext     string
+	subtree bool
+}
+
+var exportOptions = []exportOption{
+	{"Org-mode", "org", false},
+	{"Org-mode", "org", true},
+	{"Markdown", "md", false},
+	{"Markdown", "md", true},
+	{"YAML", "yaml", false},
+	{"YAML", "yaml", true},
+}
+
+func (m Model) updateExport(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "j", "down":
+			if m.exportCursor < len(exportOptions)-1 {
+				m.exportCursor++
+			}
+		case "k", "up":
+			if m.exportCursor > 0 {
+				m.exportCursor--
+			}
+		case "enter":
+			return m.doExport(exportOptions[m.exportCursor])
+		case "esc":
+			m.state = stateList
+			return m, nil
+		}
+	}
+	return m, nil
+}
+
+// doExport writes the report to flow-YYYYMMDD.<ext> in the working
+// directory, or flow-YYYYMMDD-2.<ext> and so on if that file exists.
+func (m Model) doExport(opt exportOption) (tea.Model, tea.Cmd) {
+	tasks, title, err := m.exportTasks(opt.subtree)
+	if err == nil && len(tasks) == 0 {
+		err = errors.New(m.text.NothingToExport)
+	}
+	var name string
+	if err == nil {
+		name, err = m.writeReport(opt.ext, tasks, title)
+	}
+	if err != nil {
+		m.importResult = fmt.Sprintf(m.text.ExportFailedf, err)
+		m.importIsError = true
+	} else {
+		m.importResult = fmt.Sprintf(m.text.Exportedf, len(tasks), name)
+		m.importIsError = false
+	}
+	m.state = stateImportResult
+	return m, nil
+}
+
+// exportTasks returns the tasks to export and the report title: the
+// selected task with all its sub-tasks, or the tasks of the current view.
+func (m Model) exportTasks(subtree bool) ([]model.Task, string, error) {
+	if subtree {
+		item, ok := m.list.SelectedItem().(TaskItem)
+		if !ok {
+			return nil, "", errors.New(m.text.NoTaskToExport)
+		}
+		all, err := m.store.List()
+		if err != nil {
+			return nil, "", err
+		}
+		return importer.Subtree(all, item.Task.ID), item.Task.Title, nil
+	}
+	var tasks []model.Task
+	for _, it := range m.list.Items() {
+		if ti, ok := it.(TaskItem); ok {
+			tasks = append(tasks, ti.Task)
+		}
+	}
+	title := "flow"
+	if m.view.Query != "" {
+		title += " — " + m.view.Name
+	}
+	return tasks, title, nil
+}
+
+// writeReport writes the report to a new file and returns its name.
+func (m Model) writeReport(ext string, tasks []model.Task, title string) (string, error) {
+	var deps map[int][]int
+	if ext == "yaml" {
+		var err error
+		if deps, err = m.store.AllDependencies(); err != nil {
+			return "", err
+		}
+	}
+	f, err := createExportFile(ext)
+	if err != nil {
+		return "", err
+	}
+	switch ext {
+	case "org":
+		err = importer.WriteOrg(f, tasks, title)
+	case "yaml":
+		err = importer.WriteYAML(f, tasks, deps)
+	default:
+		err = importer.WriteMarkdownReport(f, tasks, title)
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return f.Name(), err
+}
+
+// createExportFile creates the first of flow-YYYYMMDD.<ext>,
+// flow-YYYYMMDD-2.<ext> and so on that does not exist yet.
+func createExportFile(ext string) (*os.File, error) {
+	base := "flow-" + time.Now().Format("20060102")
+	name := base + "." + ext
+	for n := 2; ; n++ {
+		f, err := os.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+		name = fmt.Sprintf("%s-%d.%s", base, n, ext)
+	}
+}
+
+func (m Model) renderExport() string {
+	var lines []string
+	for i, opt := range exportOptions {
+		cursor := "  "
+		if i == m.exportCursor {
+			cursor = "> "
+		}
+		label := fmt.Sprintf(m.text.ExportViewf, opt.format)
+		if opt.subtree {
+			if item, ok := m.list.SelectedItem().(TaskItem); ok {
+				label = fmt.Sprintf(m.text.ExportSubtreeOff, opt.format, item.Task.Title)
+			} else {
+				label = fmt.Sprintf(m.text.ExportSubtreef, opt.format)
+			}
+		}
+		lines = append(lines, cursor+label)
+	}
+	return titleStyle.Render(m.text.ExportTitle) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n" +
+		statusStyle.Render(m.text.ExportHelp)
+}
@@ -0,0 +1,138 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/prompt"
+)
+
+func (m Model) updateGenerate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	templates := m.prompts.Templates()
+	switch keyMsg.String() {
+	case "j", "down":
+		if m.genCursor < len(templates)-1 {
+			m.genCursor++
+		}
+	case "k", "up":
+		if m.genCursor > 0 {
+			m.genCursor--
+		}
+	case "enter":
+		return m.usePrompt(templates[m.genCursor], false)
+	case "a":
+		if m.llm != nil {
+			return m.usePrompt(templates[m.genCursor], true)
+		}
+	case "esc":
+		m.state = stateList
+	}
+	return m, nil
+}
+
+// usePrompt renders t and copies it to the clipboard, or with send, sends
+// it to the configured model, asking for the request first if t uses one.
+func (m Model) usePrompt(t prompt.Template, send bool) (tea.Model, tea.Cmd) {
+	_, selected := m.list.SelectedItem().(TaskItem)
+	if t.NeedsTask && !selected {
+		return m, nil
+	}
+	m.importTarget = promptImportTarget(t)
+	if send && t.NeedsRequest {
+		m.genTemplate = t
+		return m.openLLMRequest()
+	}
+
+	p, err := m.renderPrompt(t, "")
+	if err != nil {
+		m.importResult = fmt.Sprintf(m.text.PromptFailedf, err)
+		m.importIsError = true
+		m.state = stateImportResult
+		return m, nil
+	}
+	if send {
+		m.genTemplate = t
+		return m.startLLM(p)
+	}
+	if err := clipboard.WriteAll(p); err != nil {
+		m.importResult = fmt.Sprintf(m.text.ClipboardWriteFailedf, err)
+		m.importIsError = true
+	} else {
+		m.importResult = fmt.Sprintf(m.text.PromptCopiedf, t.Title)
+		m.importIsError = false
+	}
+	m.state = stateImportResult
+	return m, nil
+}
+
+// promptImportTarget returns where the reply to t is imported by default.
+// Replies about the selected task are merged into it.
+func promptImportTarget(t prompt.Template) importTarget {
+	switch t.Import {
+	case "child":
+		return importAsChildren
+	case "merge":
+		return mergeIntoChildren
+	case "merge-root":
+		return mergeIntoRoots
+	case "root":
+		return importAsRoots
+	case "rewrite":
+		return rewriteSelected
+	}
+	if t.NeedsTask {
+		return mergeIntoChildren
+	}
+	return importAsRoots
+}
+
+// renderPrompt renders t about the selected task, if any.
+func (m Model) renderPrompt(t prompt.Template, request string) (string, error) {
+	var task *model.Task
+	if item, ok := m.list.SelectedItem().(TaskItem); ok {
+		task = &item.Task
+	}
+	d, err := prompt.Collect(m.store, task)
+	if err != nil {
+		return "", err
+	}
+	d.Request = request
+	return m.prompts.Render(t.Name, d)
+}
+
+func (m Model) renderGenerate() string {
+	item, selected := m.list.SelectedItem().(TaskItem)
+	var lines []string
+	for i, t := range m.prompts.Templates() {
+		cursor := "  "
+		if i == m.genCursor {
+			cursor = "> "
+		}
+		label := t.Title
+		switch {
+		case t.NeedsTask && !selected:
+			label = statusStyle.Render(label + m.text.NoTaskSelected)
+		case t.NeedsTask:
+			label += fmt.Sprintf(" (%s)", item.Task.Title)
+		}
+		lines = append(lines, cursor+label)
+	}
+
+	content := titleStyle.Render(m.text.GenerateTitle) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n"
+	if m.promptErr != nil {
+		content += errorStyle.Render(m.text.PromptLoadFailed+m.promptErr.Error()) + "\n\n"
+	}
+	help := m.text.GenerateHelp
+	if m.llm != nil {
+		help = fmt.Sprintf(m.text.GenerateSendHelpf, m.llmName())
+	}
+	return content + statusStyle.Render(help)
+}
@@ -0,0 +1,173 @@
+package ui
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nissyi-gh/flow/internal/importer"
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+var newTagStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+
+// importTarget is where the previewed tasks go.
+type importTarget int
+
+const (
+	importAsRoots     importTarget = iota
+	importAsChildren               // of the selected task
+	mergeIntoChildren              // of the selected task
+	mergeIntoRoots
+	// rewriteSelected reviews the tasks as a rewrite of the selected
+	// task's subtree instead of importing them.
+	rewriteSelected
+)
+
+// importTargets lists the choices of the import menu. Without a selected
+// task, merging into its children is the same as merging into the roots.
+func (m Model) importTargets() []importTarget {
+	if _, ok := m.list.SelectedItem().(TaskItem); ok {
+		return []importTarget{importAsRoots, importAsChildren, mergeIntoChildren, mergeIntoRoots}
+	}
+	return []importTarget{importAsRoots, importAsChildren, mergeIntoRoots}
+}
+
+// openImportPreview parses text and shows the tasks it would create.
+func (m Model) openImportPreview(text string) (tea.Model, tea.Cmd) {
+	m.importFormat = importer.Detect(text)
+	nodes, err := importer.Parse(text, m.importFormat)
+	if err == nil {
+		m.importPlan, err = importer.Plan(m.store, nodes)
+	}
+	if err != nil {
+		m.importResult = fmt.Sprintf(m.text.ParseFailedf, m.importFormat, err)
+		m.importIsError = true
+		m.state = stateImportResult
+		return m, nil
+	}
+	m.previewCursor = 0
+	m.state = stateImportPreview
+	return m, nil
+}
+
+func (m Model) updateImportPreview(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	plan := m.importPlan
+	switch keyMsg.String() {
+	case "j", "down":
+		if m.previewCursor < len(plan.Items)-1 {
+			m.previewCursor++
+		}
+	case "k", "up":
+		if m.previewCursor > 0 {
+			m.previewCursor--
+		}
+	case " ", "x":
+		plan.Toggle(m.previewCursor)
+	case "enter":
+		if plan.Count() == 0 || len(plan.Problems()) > 0 {
+			return m, nil
+		}
+		m.importCursor = max(slices.Index(m.importTargets(), m.importTarget), 0)
+		m.state = stateImportSelect
+	case "esc":
+		m.importPlan = nil
+		m.state = stateList
+	}
+	return m, nil
+}
+
+func (m Model) renderImportPreview() string {
+	plan := m.importPlan
+
+	// Keep the cursor visible when the plan is taller than the screen.
+	visible := max(m.height-14, 5)
+	start := 0
+	if m.previewCursor >= visible {
+		start = m.previewCursor - visible + 1
+	}
+	end := min(start+visible, len(plan.Items))
+
+	var lines []string
+	for i := start; i < end; i++ {
+		it := plan.Items[i]
+		cursor := "  "
+		if i == m.previewCursor {
+			cursor = "> "
+		}
+		check := "[x]"
+		if !it.Selected {
+			check = "[ ]"
+		}
+		line := cursor + check + " " + strings.Repeat("  ", it.Depth) + m.previewLabel(i)
+		if !plan.Included(i) {
+			line = statusStyle.Render(cursor + check + " " + strings.Repeat("  ", it.Depth) + it.Node.Title)
+		}
+		lines = append(lines, line)
+	}
+	if start > 0 {
+		lines = append([]string{statusStyle.Render(fmt.Sprintf(m.text.MoreAbovef, start))}, lines...)
+	}
+	if end < len(plan.Items) {
+		lines = append(lines, statusStyle.Render(fmt.Sprintf(m.text.MoreBelowf, len(plan.Items)-end)))
+	}
+
+	summary := fmt.Sprintf(m.text.PreviewCountf, plan.Count(), len(plan.Items))
+	if tags := plan.NewTags(); len(tags) > 0 {
+		summary += m.text.PreviewNewTags + newTagStyle.Render(strings.Join(tags, ", "))
+	}
+	content := titleStyle.Render(fmt.Sprintf(m.text.PreviewTitlef, m.importFormat)) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n" + summary
+	if problems := plan.Problems(); len(problems) > 0 {
+		content += "\n" + errorStyle.Render(fmt.Sprintf(m.text.PreviewProblemsf, len(problems)))
+	}
+	return content + "\n\n" +
+		statusStyle.Render(m.text.PreviewHelp)
+}
+
+// previewLabel renders the title, dates and tags of plan item i, flagging
+// new tags and problems.
+func (m Model) previewLabel(i int) string {
+	it := m.importPlan.Items[i]
+	n := it.Node
+	parts := []string{n.Title}
+	if n.Title == "" {
+		parts[0] = errorStyle.Render(m.text.NoTitle)
+	}
+	if p := (model.Task{Priority: n.Priority}).PriorityLetter(); p != "" {
+		parts[0] = "(" + p + ") " + parts[0]
+	}
+	switch n.Status {
+	case model.StatusInProgress:
+		parts = append(parts, "🔄")
+	case model.StatusCompleted:
+		parts = append(parts, "✅")
+	}
+	if n.DueDate != "" {
+		parts = append(parts, "📅 "+n.DueDate)
+	}
+	if n.ScheduledOn != "" {
+		parts = append(parts, "📌 "+n.ScheduledOn)
+	}
+	for _, name := range n.Tags {
+		if m.importPlan.IsNewTag(name) {
+			parts = append(parts, newTagStyle.Render("+#"+name))
+		} else {
+			parts = append(parts, statusStyle.Render("#"+name))
+		}
+	}
+	if len(n.DependsOn) > 0 {
+		parts = append(parts, statusStyle.Render("⛓ "+strings.Join(n.DependsOn, ", ")))
+	}
+	label := strings.Join(parts, " ")
+	for _, p := range it.Problems {
+		label += " " + errorStyle.Render("✗ "+p)
+	}
+	return label
+}
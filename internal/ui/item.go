@@ -36,7 +36,11 @@ func (i TaskItem) Title() string {
 	} else if i.Task.IsDueToday() {
 		dueMark = "📅 "
 	}
-	taskTitle := fmt.Sprintf("%s%s%s", dueMark, todayMark, i.Task.Title)
+	priority := ""
+	if p := i.Task.PriorityLetter(); p != "" {
+		priority = "(" + p + ") "
+	}
+	taskTitle := fmt.Sprintf("%s%s%s%s", dueMark, todayMark, priority, i.Task.Title)
 	if i.Task.Completed {
 		taskTitle = lipgloss.NewStyle().Strikethrough(true).Render(taskTitle)
 	}
@@ -0,0 +1,165 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nissyi-gh/flow/internal/importer"
+)
+
+// llmDeltaMsg carries a piece of the streamed reply. Messages of both kinds
+// name the channel of their request, so that those of a request that was
+// cancelled are told apart from those of the current one.
+type llmDeltaMsg struct {
+	ch   <-chan tea.Msg
+	text string
+}
+
+// llmDoneMsg ends a request with the complete reply or an error.
+type llmDoneMsg struct {
+	ch   <-chan tea.Msg
+	text string
+	err  error
+}
+
+// openLLMRequest asks for the request to fill into the prompt template
+// genTemplate.
+func (m Model) openLLMRequest() (tea.Model, tea.Cmd) {
+	m.llmInput.Reset()
+	m.state = stateLLMRequest
+	return m, m.llmInput.Focus()
+}
+
+func (m Model) updateLLMRequest(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "enter":
+			request := strings.TrimSpace(m.llmInput.Value())
+			if request == "" {
+				return m, nil
+			}
+			m.llmInput.Blur()
+			p, err := m.renderPrompt(m.genTemplate, request)
+			if err != nil {
+				m.importResult = fmt.Sprintf(m.text.PromptFailedf, err)
+				m.importIsError = true
+				m.state = stateImportResult
+				return m, nil
+			}
+			return m.startLLM(p)
+		case "esc":
+			m.llmInput.Blur()
+			m.state = stateList
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.llmInput, cmd = m.llmInput.Update(msg)
+	return m, cmd
+}
+
+// startLLM sends p to the configured endpoint and shows the reply as it
+// streams in. The request runs in its own goroutine and reports back
+// through a channel read by waitForLLM.
+func (m Model) startLLM(p string) (tea.Model, tea.Cmd) {
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := make(chan tea.Msg, 64)
+	client := m.llm
+	go func() {
+		defer close(ch)
+		send := func(msg tea.Msg) {
+			if ctx.Err() != nil {
+				return // select would pick a ready send at random
+			}
+			select {
+			case ch <- msg:
+			case <-ctx.Done():
+			}
+		}
+		text, err := client.Complete(ctx, p, func(delta string) {
+			send(llmDeltaMsg{ch: ch, text: delta})
+		})
+		send(llmDoneMsg{ch: ch, text: text, err: err})
+	}()
+
+	m.llmCancel = cancel
+	m.llmCh = ch
+	m.llmOutput = ""
+	m.state = stateLLMStream
+	return m, waitForLLM(ch)
+}
+
+func waitForLLM(ch <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		return <-ch
+	}
+}
+
+func (m Model) updateLLMStream(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case llmDeltaMsg:
+		if msg.ch != m.llmCh {
+			return m, nil // left over from a cancelled request
+		}
+		m.llmOutput += msg.text
+		return m, waitForLLM(m.llmCh)
+	case llmDoneMsg:
+		if msg.ch != m.llmCh {
+			return m, nil
+		}
+		m.llmCancel()
+		m.llmCancel, m.llmCh = nil, nil
+		if msg.err != nil {
+			m.importResult = fmt.Sprintf(m.text.LLMFailedf, msg.err)
+			m.importIsError = true
+			m.state = stateImportResult
+			return m, nil
+		}
+		if strings.TrimSpace(msg.text) == "" {
+			m.importResult = m.text.LLMEmpty
+			m.importIsError = true
+			m.state = stateImportResult
+			return m, nil
+		}
+		if m.importTarget == rewriteSelected {
+			return m.openRewrite(importer.StripCodeBlock(msg.text))
+		}
+		return m.openImportPreview(importer.StripCodeBlock(msg.text))
+	case tea.KeyMsg:
+		if msg.String() == "esc" {
+			m.llmCancel()
+			m.llmCancel, m.llmCh = nil, nil
+			m.state = stateList
+		}
+	}
+	return m, nil
+}
+
+// llmName names the configured model, or the endpoint for servers that
+// serve a single model and need none.
+func (m Model) llmName() string {
+	if m.llm.Model != "" {
+		return m.llm.Model
+	}
+	return m.llm.Endpoint
+}
+
+func (m Model) renderLLMRequest() string {
+	return titleStyle.Render(m.genTemplate.Title+" ("+m.llmName()+")") + "\n\n" +
+		m.text.RequestPrompt + "\n\n" +
+		m.llmInput.View() + "\n\n" +
+		statusStyle.Render(m.text.RequestHelp)
+}
+
+func (m Model) renderLLMStream() string {
+	// Show the tail of the reply, which is where the text arrives.
+	lines := strings.Split(m.llmOutput, "\n")
+	if visible := max(m.height-10, 5); len(lines) > visible {
+		lines = lines[len(lines)-visible:]
+	}
+	return titleStyle.Render(fmt.Sprintf(m.text.Streamingf, m.llmName())) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n" +
+		statusStyle.Render(fmt.Sprintf(m.text.StreamHelpf, len([]rune(m.llmOutput))))
+}
@@ -0,0 +1,473 @@
+package ui
+
+import "github.com/nissyi-gh/flow/internal/model"
+
+// keyHelp is an entry of the key help below the task list.
+type keyHelp struct{ key, desc string }
+
+// catalog holds the user-visible text of the TUI in one language. Fields
+// ending in "f" are fmt format strings.
+type catalog struct {
+	ErrorPrefix  string
+	PressAnyKey  string
+	NoTags       string
+	MoreAbovef   string
+	MoreBelowf   string
+	ItemName     string
+	ItemsName    string
+	FilterPrompt string
+
+	// SortModes names the sort modes in SortMode order, and ViewNames the
+	// built-in views.
+	SortModes [5]string
+	ViewNames map[string]string
+	Keys      []keyHelp
+
+	// Detail pane
+	Description   string
+	NoDescription string
+	Properties    string
+	DueDate       string
+	DependsOn     string
+	CreatedAt     string
+	DetailHelp    string
+
+	// Task title, description and due date input
+	TitlePlaceholder string
+	DescPlaceholder  string
+	NewTask          string
+	NewSubTask       string
+	AddHelp          string
+	EditDesc         string
+	EditDescHelp     string
+	SetDueDate       string
+	DueDateHelp      string
+	DatePlaceholders [3]string // year, month, day
+	DayRequired      string
+	InvalidDatef     string
+
+	QuitConfirm    string
+	QuitHelp       string
+	DeleteConfirm  string
+	DeleteChildren string
+	DeleteHelp     string
+
+	// Tag selection
+	Tags           string
+	NewTagItem     string
+	TagPlaceholder string
+	TagSelectHelp  string
+
+	// Clipboard, import and merge
+	ClipboardReadFailedf  string
+	ClipboardWriteFailedf string
+	CopiedTasks           string
+	ParseFailedf          string
+	ImportFailedf         string
+	MergeFailedf          string
+	Importedf             string
+	Mergedf               string
+	ImportTitlef          string
+	ImportAsRoots         string
+	ImportAsChildrenOff   string
+	ImportAsChildrenNone  string
+	MergeIntoChildrenOff  string
+	MergeIntoRoots        string
+	ImportSelectHelp      string
+	PreviewTitlef         string
+	PreviewCountf         string
+	PreviewNewTags        string
+	PreviewProblemsf      string
+	NoTitle               string
+	PreviewHelp           string
+
+	// AI prompts and replies
+	GenerateTitle      string
+	NoTaskSelected     string
+	PromptLoadFailed   string
+	PromptFailedf      string
+	PromptCopiedf      string
+	GenerateHelp       string
+	GenerateSendHelpf  string
+	RequestPlaceholder string
+	RequestPrompt      string
+	RequestHelp        string
+	Streamingf         string
+	StreamHelpf        string
+	LLMFailedf         string
+	LLMEmpty           string
+
+	// Rewrite review
+	RewriteTitle    string
+	RewriteCountf   string
+	RewriteHelp     string
+	NoChanges       string
+	ApplyFailedf    string
+	Appliedf        string
+	Skippedf        string
+	ChangeCreate    string
+	ChangeRename    string
+	ChangeMove      string
+	ChangeRetag     string
+	ChangeRedate    string
+	ChangeDelete    string
+	ChangeDue       string
+	ChangeScheduled string
+	TopLevel        string
+	None            string
+
+	// Export
+	ExportTitle      string
+	ExportViewf      string
+	ExportSubtreef   string
+	ExportSubtreeOff string
+	ExportHelp       string
+	NothingToExport  string
+	NoTaskToExport   string
+	ExportFailedf    string
+	Exportedf        string
+
+	// Tag filter
+	FilterTitle string
+	MatchLabel  string
+	MatchAny    string
+	MatchAll    string
+	FilterHelp  string
+
+	// Tag manager
+	ManageTags         string
+	TagUsagef          string
+	UnitTask           string
+	UnitTasks          string
+	Inherited          string
+	Merging            string
+	RenameHelp         string
+	RecolorHelp        string
+	RecolorInheritHelp string
+	MergeIntof         string
+	MergeHelp          string
+	DeleteTagf         string
+	TagManagerHelp     string
+
+	// Views
+	Views               string
+	NewView             string
+	QueryLabel          string
+	NameLabel           string
+	ViewNamePlaceholder string
+	ViewReservedf       string
+	ViewsHelp           string
+}
+
+var english = catalog{
+	ErrorPrefix:  "Error: ",
+	PressAnyKey:  "press any key to continue",
+	NoTags:       "  (no tags)",
+	MoreAbovef:   "  ↑ %d more",
+	MoreBelowf:   "  ↓ %d more",
+	ItemName:     "task",
+	ItemsName:    "tasks",
+	FilterPrompt: "Filter: ",
+
+	SortModes: [5]string{"created", "due", "status", "title", "updated"},
+	ViewNames: map[string]string{"all": "all", "today": "today"},
+	Keys: []keyHelp{
+		{"a/n", "add"}, {"s", "sub-task"}, {"p", "progress"}, {"enter/x", "done"}, {"d", "delete"},
+		{"t", "today"}, {"D", "due date"}, {"e", "edit desc"}, {"T", "tags"}, {"M", "manage tags"},
+		{"c", "copy"}, {"E", "export"}, {"v", "view"}, {"f", "tag filter"}, {"o", "sort"}, {"g", "AI prompt"}, {"G", "import"}, {"R", "rewrite"}, {"/", "filter"}, {"q", "quit"},
+	},
+
+	Description:   "Description",
+	NoDescription: "(no description)",
+	Properties:    "Property",
+	DueDate:       "due_date",
+	DependsOn:     "depends_on",
+	CreatedAt:     "created_at",
+	DetailHelp:    "e: edit description  T: tags",
+
+	TitlePlaceholder: "Task title...",
+	DescPlaceholder:  "Task description...",
+	NewTask:          "New Task",
+	NewSubTask:       "New Sub-task",
+	AddHelp:          "enter: save • esc: cancel",
+	EditDesc:         "Edit Description",
+	EditDescHelp:     "esc: save • ctrl+c: cancel",
+	SetDueDate:       "Set Due Date",
+	DueDateHelp:      "tab/→: next field • enter: save • esc: cancel",
+	DatePlaceholders: [3]string{"YYYY", "MM", "DD"},
+	DayRequired:      "day is required",
+	InvalidDatef:     "invalid date: %s",
+
+	QuitConfirm:    "Quit flow?",
+	QuitHelp:       "y: quit • n/esc: cancel",
+	DeleteConfirm:  "Delete Task?",
+	DeleteChildren: "(its sub-tasks are deleted too)",
+	DeleteHelp:     "y: delete • n/esc: cancel",
+
+	Tags:           "Tags",
+	NewTagItem:     "+ New tag...",
+	TagPlaceholder: "New tag name (use / to nest, e.g. work/clientA)...",
+	TagSelectHelp:  "j/k: navigate  enter/space: toggle  esc: done",
+
+	ClipboardReadFailedf:  "Could not read the clipboard: %v",
+	ClipboardWriteFailedf: "Could not copy to the clipboard: %v",
+	CopiedTasks:           "Copied the task list to the clipboard",
+	ParseFailedf:          "Could not read %s: %v",
+	ImportFailedf:         "Could not import %s: %v",
+	MergeFailedf:          "Could not merge %s: %v",
+	Importedf:             "✓ Imported %d tasks",
+	Mergedf:               "✓ %d created, %d updated, %d unchanged",
+	ImportTitlef:          "Import %s",
+	ImportAsRoots:         "Import as top-level tasks",
+	ImportAsChildrenOff:   "Import as sub-tasks of \"%s\"",
+	ImportAsChildrenNone:  "Import as sub-tasks of the selected task (none selected)",
+	MergeIntoChildrenOff:  "Merge into the sub-tasks of \"%s\" (updates tasks with the same title or id)",
+	MergeIntoRoots:        "Merge into the top-level tasks (updates tasks with the same title or id)",
+	ImportSelectHelp:      "j/k: navigate  enter: import  esc: back",
+	PreviewTitlef:         "Import Preview (%s)",
+	PreviewCountf:         "%d / %d tasks to import",
+	PreviewNewTags:        "  new tags: ",
+	PreviewProblemsf:      "✗ %d problems. Fix them or deselect the tasks",
+	NoTitle:               "(no title)",
+	PreviewHelp:           "j/k: navigate  space/x: toggle branch  enter: continue  esc: cancel",
+
+	GenerateTitle:      "AI Task Breakdown",
+	NoTaskSelected:     " (no task selected)",
+	PromptLoadFailed:   "Could not load the prompt templates: ",
+	PromptFailedf:      "Could not render the prompt: %v",
+	PromptCopiedf:      "Copied the \"%s\" prompt to the clipboard",
+	GenerateHelp:       "j/k: navigate  enter: copy prompt  esc: cancel",
+	GenerateSendHelpf:  "j/k: navigate  enter: copy prompt  a: send to %s  esc: cancel",
+	RequestPlaceholder: "What to break down, e.g. prepare for the move...",
+	RequestPrompt:      "What should the AI do?",
+	RequestHelp:        "enter: send  esc: cancel",
+	Streamingf:         "AI (%s) writing…",
+	StreamHelpf:        "%d characters received  esc: cancel",
+	LLMFailedf:         "Could not get a reply from the AI: %v",
+	LLMEmpty:           "The AI replied with nothing",
+
+	RewriteTitle:    "Rewrite Review",
+	RewriteCountf:   "%d / %d changes to apply",
+	RewriteHelp:     "j/k: navigate  space/x: accept/reject  a: all  n: none  enter: apply  esc: cancel",
+	NoChanges:       "Nothing to change",
+	ApplyFailedf:    "Could not apply the changes: %v",
+	Appliedf:        "✓ Applied %d changes",
+	Skippedf:        " (skipped %d that depend on a rejected change)",
+	ChangeCreate:    "+ add",
+	ChangeRename:    "~ rename",
+	ChangeMove:      "↳ move",
+	ChangeRetag:     "# tags",
+	ChangeRedate:    "📅 dates",
+	ChangeDelete:    "- delete",
+	ChangeDue:       "due",
+	ChangeScheduled: "scheduled",
+	TopLevel:        "(top level)",
+	None:            "(none)",
+
+	ExportTitle:      "Export Report",
+	ExportViewf:      "%s (tasks in view)",
+	ExportSubtreef:   "%s (selected task and its sub-tasks)",
+	ExportSubtreeOff: "%s (\"%s\" and its sub-tasks)",
+	ExportHelp:       "j/k: navigate  enter: select  esc: cancel",
+	NothingToExport:  "no tasks to export",
+	NoTaskToExport:   "no task is selected",
+	ExportFailedf:    "Could not export: %v",
+	Exportedf:        "✓ Wrote %d tasks to %s",
+
+	FilterTitle: "Filter by Tag",
+	MatchLabel:  "Match: ",
+	MatchAny:    "any (OR)",
+	MatchAll:    "all (AND)",
+	FilterHelp:  "j/k: navigate  enter/space: include/exclude/clear  m: AND/OR  c: clear all  esc: apply",
+
+	ManageTags:         "Manage Tags",
+	TagUsagef:          "%d %s · color %s",
+	UnitTask:           "task",
+	UnitTasks:          "tasks",
+	Inherited:          " (inherited)",
+	Merging:            " ← merging",
+	RenameHelp:         "enter: rename • esc: cancel",
+	RecolorHelp:        "h/j/k/l: move • enter: apply • esc: cancel",
+	RecolorInheritHelp: "h/j/k/l: move • enter: apply • i: inherit from parent • esc: cancel",
+	MergeIntof:         "Merge [%s] into...",
+	MergeHelp:          "j/k: choose target • enter: merge • esc: cancel",
+	DeleteTagf:         "Delete [%s]? It is used by %d task(s).",
+	TagManagerHelp:     "j/k: navigate  r: rename  c: color  m: merge  d: delete  esc: back",
+
+	Views:               "Views",
+	NewView:             "+ New view...",
+	QueryLabel:          "Query: ",
+	NameLabel:           "Name:  ",
+	ViewNamePlaceholder: "View name...",
+	ViewReservedf:       "view name %q is reserved",
+	ViewsHelp:           "j/k: navigate  enter: select  d: delete  esc: back",
+}
+
+var japanese = catalog{
+	ErrorPrefix:  "エラー: ",
+	PressAnyKey:  "何かキーを押すと戻ります",
+	NoTags:       "  (タグなし)",
+	MoreAbovef:   "  ↑ 他 %d 件",
+	MoreBelowf:   "  ↓ 他 %d 件",
+	ItemName:     "タスク",
+	ItemsName:    "タスク",
+	FilterPrompt: "絞り込み: ",
+
+	SortModes: [5]string{"作成順", "期限順", "状態順", "名前順", "更新順"},
+	ViewNames: map[string]string{"all": "すべて", "today": "今日"},
+	Keys: []keyHelp{
+		{"a/n", "追加"}, {"s", "子タスク"}, {"p", "着手"}, {"enter/x", "完了"}, {"d", "削除"},
+		{"t", "今日"}, {"D", "期限"}, {"e", "説明"}, {"T", "タグ"}, {"M", "タグ管理"},
+		{"c", "コピー"}, {"E", "エクスポート"}, {"v", "ビュー"}, {"f", "タグ絞り込み"}, {"o", "並び順"}, {"g", "AIプロンプト"}, {"G", "インポート"}, {"R", "書き換え"}, {"/", "検索"}, {"q", "終了"},
+	},
+
+	Description:   "説明",
+	NoDescription: "(説明なし)",
+	Properties:    "プロパティ",
+	DueDate:       "期限",
+	DependsOn:     "依存",
+	CreatedAt:     "作成日時",
+	DetailHelp:    "e: 説明を編集  T: タグ",
+
+	TitlePlaceholder: "タスク名...",
+	DescPlaceholder:  "タスクの説明...",
+	NewTask:          "新しいタスク",
+	NewSubTask:       "新しい子タスク",
+	AddHelp:          "enter: 保存 • esc: キャンセル",
+	EditDesc:         "説明を編集",
+	EditDescHelp:     "esc: 保存 • ctrl+c: キャンセル",
+	SetDueDate:       "期限を設定",
+	DueDateHelp:      "tab/→: 次の欄 • enter: 保存 • esc: キャンセル",
+	DatePlaceholders: [3]string{"年", "月", "日"},
+	DayRequired:      "日を入力してください",
+	InvalidDatef:     "日付が正しくありません: %s",
+
+	QuitConfirm:    "flow を終了しますか?",
+	QuitHelp:       "y: 終了 • n/esc: キャンセル",
+	DeleteConfirm:  "タスクを削除しますか?",
+	DeleteChildren: "(子タスクも削除されます)",
+	DeleteHelp:     "y: 削除 • n/esc: キャンセル",
+
+	Tags:           "タグ",
+	NewTagItem:     "+ 新しいタグ...",
+	TagPlaceholder: "新しいタグ名 (/ で階層化 例: work/clientA)...",
+	TagSelectHelp:  "j/k: 移動  enter/space: 付け外し  esc: 完了",
+
+	ClipboardReadFailedf:  "クリップボードの読み取りに失敗しました: %v",
+	ClipboardWriteFailedf: "クリップボードへのコピーに失敗しました: %v",
+	CopiedTasks:           "タスク一覧をクリップボードにコピーしました",
+	ParseFailedf:          "%sの読み込みに失敗しました: %v",
+	ImportFailedf:         "%sのインポートに失敗しました: %v",
+	MergeFailedf:          "%sの統合に失敗しました: %v",
+	Importedf:             "✓ %d 件のタスクをインポートしました",
+	Mergedf:               "✓ 作成 %d 件・更新 %d 件・変更なし %d 件",
+	ImportTitlef:          "%s のインポート",
+	ImportAsRoots:         "ルートタスクとしてインポート",
+	ImportAsChildrenOff:   "「%s」の子タスクとしてインポート",
+	ImportAsChildrenNone:  "選択中タスクの子タスクとしてインポート (未選択)",
+	MergeIntoChildrenOff:  "「%s」の子タスクと統合 (同じタイトル・id のタスクを更新)",
+	MergeIntoRoots:        "ルートタスクと統合 (同じタイトル・id のタスクを更新)",
+	ImportSelectHelp:      "j/k: 移動  enter: インポート  esc: 戻る",
+	PreviewTitlef:         "インポートのプレビュー (%s)",
+	PreviewCountf:         "%d / %d 件をインポート",
+	PreviewNewTags:        "  新しいタグ: ",
+	PreviewProblemsf:      "✗ %d 件の問題があります。修正するか選択を外してください",
+	NoTitle:               "(タイトルなし)",
+	PreviewHelp:           "j/k: 移動  space/x: 枝ごと選択/解除  enter: 次へ  esc: キャンセル",
+
+	GenerateTitle:      "AIでタスクを分解",
+	NoTaskSelected:     " (タスク未選択)",
+	PromptLoadFailed:   "テンプレートの読み込みに失敗しました: ",
+	PromptFailedf:      "プロンプトの生成に失敗しました: %v",
+	PromptCopiedf:      "「%s」のプロンプトをクリップボードにコピーしました",
+	GenerateHelp:       "j/k: 移動  enter: プロンプトをコピー  esc: キャンセル",
+	GenerateSendHelpf:  "j/k: 移動  enter: プロンプトをコピー  a: %s に送信  esc: キャンセル",
+	RequestPlaceholder: "分解したいこと 例: 引っ越しの準備...",
+	RequestPrompt:      "AIへの依頼内容を入力してください",
+	RequestHelp:        "enter: 送信  esc: キャンセル",
+	Streamingf:         "AI (%s) 生成中…",
+	StreamHelpf:        "%d 文字受信  esc: キャンセル",
+	LLMFailedf:         "AIの応答の取得に失敗しました: %v",
+	LLMEmpty:           "AIの応答が空でした",
+
+	RewriteTitle:    "書き換えの確認",
+	RewriteCountf:   "%d / %d 件の変更を適用",
+	RewriteHelp:     "j/k: 移動  space/x: 採用/却下  a: すべて採用  n: すべて却下  enter: 適用  esc: キャンセル",
+	NoChanges:       "変更はありません",
+	ApplyFailedf:    "変更の適用に失敗しました: %v",
+	Appliedf:        "✓ %d 件の変更を適用しました",
+	Skippedf:        " (%d 件は却下した変更に依存するためスキップ)",
+	ChangeCreate:    "+ 追加",
+	ChangeRename:    "~ 名前変更",
+	ChangeMove:      "↳ 移動",
+	ChangeRetag:     "# タグ",
+	ChangeRedate:    "📅 日付",
+	ChangeDelete:    "- 削除",
+	ChangeDue:       "期限",
+	ChangeScheduled: "予定",
+	TopLevel:        "(トップレベル)",
+	None:            "(なし)",
+
+	ExportTitle:      "レポートのエクスポート",
+	ExportViewf:      "%s (表示中のタスク)",
+	ExportSubtreef:   "%s (選択中タスクとその子タスク)",
+	ExportSubtreeOff: "%s (「%s」とその子タスク)",
+	ExportHelp:       "j/k: 移動  enter: 選択  esc: キャンセル",
+	NothingToExport:  "エクスポートするタスクがありません",
+	NoTaskToExport:   "タスクが選択されていません",
+	ExportFailedf:    "エクスポートに失敗しました: %v",
+	Exportedf:        "✓ %d 件のタスクを %s に書き出しました",
+
+	FilterTitle: "タグで絞り込み",
+	MatchLabel:  "条件: ",
+	MatchAny:    "いずれか (OR)",
+	MatchAll:    "すべて (AND)",
+	FilterHelp:  "j/k: 移動  enter/space: 含める/除く/解除  m: AND/OR  c: すべて解除  esc: 適用",
+
+	ManageTags:         "タグの管理",
+	TagUsagef:          "%d %s · 色 %s",
+	UnitTask:           "件",
+	UnitTasks:          "件",
+	Inherited:          " (親から継承)",
+	Merging:            " ← 統合元",
+	RenameHelp:         "enter: 名前を変更 • esc: キャンセル",
+	RecolorHelp:        "h/j/k/l: 移動 • enter: 適用 • esc: キャンセル",
+	RecolorInheritHelp: "h/j/k/l: 移動 • enter: 適用 • i: 親の色を継承 • esc: キャンセル",
+	MergeIntof:         "[%s] の統合先を選択...",
+	MergeHelp:          "j/k: 統合先を選択 • enter: 統合 • esc: キャンセル",
+	DeleteTagf:         "[%s] を削除しますか? %d 件のタスクで使われています。",
+	TagManagerHelp:     "j/k: 移動  r: 名前変更  c: 色  m: 統合  d: 削除  esc: 戻る",
+
+	Views:               "ビュー",
+	NewView:             "+ 新しいビュー...",
+	QueryLabel:          "条件: ",
+	NameLabel:           "名前: ",
+	ViewNamePlaceholder: "ビュー名...",
+	ViewReservedf:       "ビュー名 %q は予約されています",
+	ViewsHelp:           "j/k: 移動  enter: 選択  d: 削除  esc: 戻る",
+}
+
+// catalogFor returns the text in lang, "en" or "ja", falling back to
+// English.
+func catalogFor(lang string) *catalog {
+	if lang == "ja" {
+		return &japanese
+	}
+	return &english
+}
+
+// sortMode names s in the title bar.
+func (c *catalog) sortMode(s SortMode) string {
+	if int(s) < 0 || int(s) >= len(c.SortModes) {
+		s = SortCreated
+	}
+	return c.SortModes[s]
+}
+
+// viewName names v, translating the built-in views.
+func (c *catalog) viewName(v model.View) string {
+	if name, ok := c.ViewNames[v.Name]; ok && v.ID == 0 {
+		return name
+	}
+	return v.Name
+}
@@ -0,0 +1,180 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nissyi-gh/flow/internal/importer"
+)
+
+var addStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("148"))
+
+// openRewrite compares text, a rewritten copy of the selected task's
+// subtree, with the subtree and lists the changes for review.
+func (m Model) openRewrite(text string) (tea.Model, tea.Cmd) {
+	item, ok := m.list.SelectedItem().(TaskItem)
+	if !ok {
+		m.state = stateList
+		return m, nil
+	}
+	format := importer.Detect(text)
+	nodes, err := importer.Parse(text, format)
+	var rw *importer.Rewrite
+	if err == nil {
+		rw, err = importer.DiffSubtree(m.store, item.Task.ID, nodes)
+	}
+	if err != nil {
+		m.importResult = fmt.Sprintf(m.text.ParseFailedf, format, err)
+		m.importIsError = true
+		m.state = stateImportResult
+		return m, nil
+	}
+	if len(rw.Changes) == 0 {
+		m.importResult = m.text.NoChanges
+		m.importIsError = false
+		m.state = stateImportResult
+		return m, nil
+	}
+
+	m.rewrite = rw
+	m.rewriteAccepted = make([]bool, len(rw.Changes))
+	for i := range m.rewriteAccepted {
+		m.rewriteAccepted[i] = true
+	}
+	m.rewriteCursor = 0
+	m.state = stateRewrite
+	return m, nil
+}
+
+func (m Model) updateRewrite(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "j", "down":
+		if m.rewriteCursor < len(m.rewrite.Changes)-1 {
+			m.rewriteCursor++
+		}
+	case "k", "up":
+		if m.rewriteCursor > 0 {
+			m.rewriteCursor--
+		}
+	case " ", "x":
+		m.rewriteAccepted[m.rewriteCursor] = !m.rewriteAccepted[m.rewriteCursor]
+	case "a", "n":
+		for i := range m.rewriteAccepted {
+			m.rewriteAccepted[i] = keyMsg.String() == "a"
+		}
+	case "enter":
+		res, err := m.rewrite.Apply(m.store, m.rewriteAccepted)
+		m.rewrite, m.rewriteAccepted = nil, nil
+		if err != nil {
+			m.importResult = fmt.Sprintf(m.text.ApplyFailedf, err)
+			m.importIsError = true
+		} else {
+			m.importResult = fmt.Sprintf(m.text.Appliedf, res.Applied)
+			if res.Skipped > 0 {
+				m.importResult += fmt.Sprintf(m.text.Skippedf, res.Skipped)
+			}
+			m.importIsError = false
+		}
+		m.state = stateImportResult
+	case "esc":
+		m.rewrite, m.rewriteAccepted = nil, nil
+		m.state = stateList
+	}
+	return m, nil
+}
+
+func (m Model) renderRewrite() string {
+	changes := m.rewrite.Changes
+
+	// Keep the cursor visible when the list is taller than the screen.
+	visible := max(m.height-12, 5)
+	start := 0
+	if m.rewriteCursor >= visible {
+		start = m.rewriteCursor - visible + 1
+	}
+	end := min(start+visible, len(changes))
+
+	var lines []string
+	accepted := 0
+	for i := start; i < end; i++ {
+		cursor := "  "
+		if i == m.rewriteCursor {
+			cursor = "> "
+		}
+		if m.rewriteAccepted[i] {
+			lines = append(lines, cursor+"[x] "+m.changeLabel(changes[i]))
+		} else {
+			lines = append(lines, statusStyle.Render(cursor+"[ ] "+m.changeLabel(changes[i])))
+		}
+	}
+	for _, ok := range m.rewriteAccepted {
+		if ok {
+			accepted++
+		}
+	}
+	if start > 0 {
+		lines = append([]string{statusStyle.Render(fmt.Sprintf(m.text.MoreAbovef, start))}, lines...)
+	}
+	if end < len(changes) {
+		lines = append(lines, statusStyle.Render(fmt.Sprintf(m.text.MoreBelowf, len(changes)-end)))
+	}
+
+	return titleStyle.Render(m.text.RewriteTitle) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n" +
+		fmt.Sprintf(m.text.RewriteCountf, accepted, len(changes)) + "\n\n" +
+		statusStyle.Render(m.text.RewriteHelp)
+}
+
+// changeLabel describes c in one line.
+func (m Model) changeLabel(c importer.Change) string {
+	t := m.text
+	switch c.Kind {
+	case importer.ChangeCreate:
+		return addStyle.Render(t.ChangeCreate) + "  " + c.Title + statusStyle.Render("  → "+m.parentLabel(c.NewParent))
+	case importer.ChangeRename:
+		return t.ChangeRename + "  " + c.Title + " → " + c.NewTitle
+	case importer.ChangeMove:
+		return t.ChangeMove + "  " + c.Title + ": " + m.parentLabel(c.OldParent) + " → " + m.parentLabel(c.NewParent)
+	case importer.ChangeRetag:
+		return t.ChangeRetag + "  " + c.Title + ": " + m.tagsLabel(c.OldTags) + " → " + m.tagsLabel(c.NewTags)
+	case importer.ChangeRedate:
+		var parts []string
+		if c.OldDueDate != c.NewDueDate {
+			parts = append(parts, t.ChangeDue+" "+m.dateLabel(c.OldDueDate)+" → "+m.dateLabel(c.NewDueDate))
+		}
+		if c.OldScheduledOn != c.NewScheduledOn {
+			parts = append(parts, t.ChangeScheduled+" "+m.dateLabel(c.OldScheduledOn)+" → "+m.dateLabel(c.NewScheduledOn))
+		}
+		return t.ChangeRedate + "  " + c.Title + ": " + strings.Join(parts, ", ")
+	case importer.ChangeDelete:
+		return errorStyle.Render(t.ChangeDelete) + "  " + c.Title
+	}
+	return c.Title
+}
+
+func (m Model) parentLabel(title string) string {
+	if title == "" {
+		return m.text.TopLevel
+	}
+	return title
+}
+
+func (m Model) tagsLabel(tags []string) string {
+	if len(tags) == 0 {
+		return m.text.None
+	}
+	return "#" + strings.Join(tags, " #")
+}
+
+func (m Model) dateLabel(date string) string {
+	if date == "" {
+		return m.text.None
+	}
+	return date
+}
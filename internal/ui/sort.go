@@ -0,0 +1,103 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// SortMode controls the order of sibling tasks in the tree.
+type SortMode int
+
+const (
+	SortCreated SortMode = iota
+	SortDue
+	SortStatus
+	SortTitle
+	SortUpdated
+)
+
+var sortModeNames = []string{"created", "due", "status", "title", "updated"}
+
+// String returns the name used in the title bar and the settings table.
+func (s SortMode) String() string {
+	if int(s) < 0 || int(s) >= len(sortModeNames) {
+		return sortModeNames[SortCreated]
+	}
+	return sortModeNames[s]
+}
+
+// Next returns the sort mode that follows s, wrapping around.
+func (s SortMode) Next() SortMode {
+	return SortMode((int(s) + 1) % len(sortModeNames))
+}
+
+// ParseSortMode converts a name produced by String back to a SortMode.
+// Unknown names fall back to SortCreated.
+func ParseSortMode(name string) SortMode {
+	for i, n := range sortModeNames {
+		if n == name {
+			return SortMode(i)
+		}
+	}
+	return SortCreated
+}
+
+// statusRank orders in-progress work first and completed work last.
+func statusRank(s model.TaskStatus) int {
+	switch s {
+	case model.StatusInProgress:
+		return 0
+	case model.StatusNotStarted:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// priorityRank orders priorities A to Z, then tasks without one.
+func priorityRank(p int) int {
+	if p == 0 {
+		return 27
+	}
+	return p
+}
+
+// sortSiblings orders tasks in place. The sort is stable, so ties keep
+// their creation order.
+func sortSiblings(tasks []model.Task, mode SortMode) {
+	var less func(a, b model.Task) bool
+	switch mode {
+	case SortDue:
+		// Tasks without a due date go last.
+		less = func(a, b model.Task) bool {
+			if a.DueDate == nil || b.DueDate == nil {
+				return a.DueDate != nil && b.DueDate == nil
+			}
+			return *a.DueDate < *b.DueDate
+		}
+	case SortStatus:
+		// Within a status, higher priorities come first.
+		less = func(a, b model.Task) bool {
+			if ra, rb := statusRank(a.Status), statusRank(b.Status); ra != rb {
+				return ra < rb
+			}
+			return priorityRank(a.Priority) < priorityRank(b.Priority)
+		}
+	case SortTitle:
+		less = func(a, b model.Task) bool {
+			return strings.ToLower(a.Title) < strings.ToLower(b.Title)
+		}
+	case SortUpdated:
+		// Most recently updated first.
+		less = func(a, b model.Task) bool {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+	default:
+		return
+	}
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return less(tasks[i], tasks[j])
+	})
+}
@@ -0,0 +1,60 @@
+package ui
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestSortSiblings(t *testing.T) {
+	date := func(s string) *string { return &s }
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Tasks are listed in creation order.
+	tasks := []model.Task{
+		{Title: "b", Status: model.StatusCompleted, Completed: true, DueDate: date("2026-03-01"), UpdatedAt: base.Add(2 * time.Hour)},
+		{Title: "D", Status: model.StatusNotStarted, UpdatedAt: base},
+		{Title: "a", Status: model.StatusInProgress, Priority: 2, DueDate: date("2026-02-01"), UpdatedAt: base.Add(3 * time.Hour)},
+		{Title: "C", Status: model.StatusNotStarted, Priority: 1, UpdatedAt: base.Add(time.Hour)},
+		{Title: "e", Status: model.StatusInProgress, DueDate: date("2026-02-01"), UpdatedAt: base.Add(time.Hour)},
+	}
+	for _, tc := range []struct {
+		mode SortMode
+		want string
+	}{
+		{SortCreated, "b D a C e"},
+		{SortDue, "a e b D C"},
+		{SortStatus, "a e C D b"},
+		{SortTitle, "a b C D e"},
+		{SortUpdated, "a b C e D"},
+		{SortMode(9), "b D a C e"},
+	} {
+		sorted := append([]model.Task(nil), tasks...)
+		sortSiblings(sorted, tc.mode)
+		var titles []string
+		for _, task := range sorted {
+			titles = append(titles, task.Title)
+		}
+		if got := strings.Join(titles, " "); got != tc.want {
+			t.Errorf("sort by %s = %s, want %s", tc.mode, got, tc.want)
+		}
+	}
+}
+
+func TestSortModeNames(t *testing.T) {
+	for _, s := range []SortMode{SortCreated, SortDue, SortStatus, SortTitle, SortUpdated} {
+		if got := ParseSortMode(s.String()); got != s {
+			t.Errorf("ParseSortMode(%q) = %v, want %v", s.String(), got, s)
+		}
+		if got, want := catalogFor("ja").sortMode(s), japanese.SortModes[s]; got != want {
+			t.Errorf("ja sortMode(%s) = %q, want %q", s, got, want)
+		}
+	}
+	if got := SortUpdated.Next(); got != SortCreated {
+		t.Errorf("SortUpdated.Next() = %v, want SortCreated", got)
+	}
+	if got := catalogFor("en").sortMode(SortMode(-1)); got != "created" {
+		t.Errorf("sortMode of an unknown mode = %q, want created", got)
+	}
+}
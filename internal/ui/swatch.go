@@ -0,0 +1,71 @@
+package ui
+
+import (
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+const swatchColumns = 16
+
+// colorPicker is a 16x16 grid of the 256 terminal colors.
+type colorPicker struct {
+	cursor int // selected color code, 0-255
+}
+
+func newColorPicker(color string) colorPicker {
+	n, err := strconv.Atoi(color)
+	if err != nil || n < 0 || n > 255 {
+		n = 0
+	}
+	return colorPicker{cursor: n}
+}
+
+// Value returns the selected color as a lipgloss color code.
+func (c colorPicker) Value() string {
+	return strconv.Itoa(c.cursor)
+}
+
+func (c colorPicker) Update(msg tea.Msg) (colorPicker, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "h", "left":
+			if c.cursor%swatchColumns > 0 {
+				c.cursor--
+			}
+		case "l", "right":
+			if c.cursor%swatchColumns < swatchColumns-1 {
+				c.cursor++
+			}
+		case "k", "up":
+			if c.cursor >= swatchColumns {
+				c.cursor -= swatchColumns
+			}
+		case "j", "down":
+			if c.cursor < 256-swatchColumns {
+				c.cursor += swatchColumns
+			}
+		}
+	}
+	return c, nil
+}
+
+func (c colorPicker) View() string {
+	var sb strings.Builder
+	for i := 0; i < 256; i++ {
+		cell := "  "
+		if i == c.cursor {
+			cell = "[]"
+		}
+		sb.WriteString(lipgloss.NewStyle().
+			Background(lipgloss.Color(strconv.Itoa(i))).
+			Foreground(lipgloss.Color("255")).
+			Render(cell))
+		if i%swatchColumns == swatchColumns-1 && i != 255 {
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,188 @@
+package ui
+
+import (
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+// tagFilter narrows the tree to tasks carrying (or lacking) selected tags.
+type tagFilter struct {
+	include  map[int]model.Tag
+	exclude  map[int]model.Tag
+	matchAll bool // true: every included tag is required, false: any one is enough
+}
+
+func newTagFilter() tagFilter {
+	return tagFilter{
+		include: make(map[int]model.Tag),
+		exclude: make(map[int]model.Tag),
+	}
+}
+
+func (f tagFilter) active() bool {
+	return len(f.include) > 0 || len(f.exclude) > 0
+}
+
+// matches reports whether t passes the filter. A filtered tag also matches
+// tasks carrying a tag nested below it.
+func (f tagFilter) matches(t model.Task) bool {
+	carries := func(name string) bool {
+		for _, tag := range t.Tags {
+			if tag.Within(name) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, tag := range f.exclude {
+		if carries(tag.Name) {
+			return false
+		}
+	}
+	if len(f.include) == 0 {
+		return true
+	}
+	for _, tag := range f.include {
+		if carries(tag.Name) && !f.matchAll {
+			return true
+		}
+		if !carries(tag.Name) && f.matchAll {
+			return false
+		}
+	}
+	return f.matchAll
+}
+
+// cycle moves a tag through unfiltered → included → excluded → unfiltered.
+func (f tagFilter) cycle(tag model.Tag) {
+	if _, ok := f.include[tag.ID]; ok {
+		delete(f.include, tag.ID)
+		f.exclude[tag.ID] = tag
+	} else if _, ok := f.exclude[tag.ID]; ok {
+		delete(f.exclude, tag.ID)
+	} else {
+		f.include[tag.ID] = tag
+	}
+}
+
+// refresh replaces the filtered tags with their current versions in tags,
+// after a rename, and drops the ones that no longer exist.
+func (f tagFilter) refresh(tags []model.Tag) {
+	byID := make(map[int]model.Tag, len(tags))
+	for _, tag := range tags {
+		byID[tag.ID] = tag
+	}
+	for _, set := range []map[int]model.Tag{f.include, f.exclude} {
+		for id := range set {
+			if tag, ok := byID[id]; ok {
+				set[id] = tag
+			} else {
+				delete(set, id)
+			}
+		}
+	}
+}
+
+// mark renders the filter state of a tag as a checkbox.
+func (f tagFilter) mark(id int) string {
+	if _, ok := f.include[id]; ok {
+		return "[+]"
+	}
+	if _, ok := f.exclude[id]; ok {
+		return "[-]"
+	}
+	return "[ ]"
+}
+
+// summary describes the filter for the title bar, e.g. "work & urgent !home".
+func (f tagFilter) summary() string {
+	sep := " | "
+	if f.matchAll {
+		sep = " & "
+	}
+	var inc, exc []string
+	for _, t := range f.include {
+		inc = append(inc, t.Name)
+	}
+	for _, t := range f.exclude {
+		exc = append(exc, "!"+t.Name)
+	}
+	sort.Strings(inc)
+	sort.Strings(exc)
+	parts := []string{}
+	if len(inc) > 0 {
+		parts = append(parts, strings.Join(inc, sep))
+	}
+	parts = append(parts, exc...)
+	return strings.Join(parts, " ")
+}
+
+func (m Model) openTagFilter() (tea.Model, tea.Cmd) {
+	allTags, err := m.store.ListTags()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.allTags = allTags
+	m.tagCursor = 0
+	m.state = stateTagFilter
+	return m, nil
+}
+
+func (m Model) updateTagFilter(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "j", "down":
+			if m.tagCursor < len(m.allTags)-1 {
+				m.tagCursor++
+			}
+		case "k", "up":
+			if m.tagCursor > 0 {
+				m.tagCursor--
+			}
+		case "enter", " ", "x":
+			if m.tagCursor < len(m.allTags) {
+				m.tagFilter.cycle(m.allTags[m.tagCursor])
+			}
+		case "m":
+			m.tagFilter.matchAll = !m.tagFilter.matchAll
+		case "c":
+			m.tagFilter = newTagFilter()
+		case "esc":
+			m.state = stateList
+			return m, m.loadTasks
+		}
+	}
+	return m, nil
+}
+
+func (m Model) renderTagFilter() string {
+	var lines []string
+	for i, tag := range m.allTags {
+		cursor := "  "
+		if i == m.tagCursor {
+			cursor = "> "
+		}
+		mark := m.tagFilter.mark(tag.ID)
+		badge := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(tag.Color)).
+			Render(tag.Leaf())
+		lines = append(lines, cursor+mark+" "+tagIndent(tag)+badge)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, statusStyle.Render(m.text.NoTags))
+	}
+
+	mode := m.text.MatchAny
+	if m.tagFilter.matchAll {
+		mode = m.text.MatchAll
+	}
+	return titleStyle.Render(m.text.FilterTitle) + "\n\n" +
+		strings.Join(lines, "\n") + "\n\n" +
+		m.text.MatchLabel + mode + "\n\n" +
+		statusStyle.Render(m.text.FilterHelp)
+}
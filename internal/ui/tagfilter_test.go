@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"testing"
+
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+func TestTagFilterRefresh(t *testing.T) {
+	work, home := model.Tag{ID: 1, Name: "work"}, model.Tag{ID: 2, Name: "home"}
+	f := newTagFilter()
+	f.cycle(work) // included
+	f.cycle(home)
+	f.cycle(home) // excluded
+
+	// work is renamed to job, and home is deleted.
+	job := model.Tag{ID: 1, Name: "job"}
+	f.refresh([]model.Tag{job})
+
+	task := func(tags ...model.Tag) model.Task { return model.Task{Tags: tags} }
+	if !f.matches(task(job)) {
+		t.Error("a task tagged with the renamed tag is filtered out")
+	}
+	if f.matches(task(work)) {
+		t.Error("a task tagged with the old name matches")
+	}
+	if _, ok := f.exclude[home.ID]; ok {
+		t.Error("the deleted tag is still excluded")
+	}
+}
@@ -0,0 +1,249 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/nissyi-gh/flow/internal/model"
+)
+
+type tagMgrMode int
+
+const (
+	tagMgrBrowse tagMgrMode = iota
+	tagMgrRename
+	tagMgrRecolor
+	tagMgrMerge
+	tagMgrDelete
+)
+
+func (m Model) openTagManager() (tea.Model, tea.Cmd) {
+	m.state = stateTagManager
+	m.tagMgrMode = tagMgrBrowse
+	m.tagCursor = 0
+	return m.reloadTagManager()
+}
+
+// reloadTagManager refreshes the tag list and usage counts, keeping the
+// cursor in range.
+func (m Model) reloadTagManager() (tea.Model, tea.Cmd) {
+	allTags, err := m.store.ListTags()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	usage, err := m.store.TagUsage()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.allTags = allTags
+	m.tagUsage = usage
+	m.tagFilter.refresh(allTags)
+	if m.tagCursor >= len(m.allTags) {
+		m.tagCursor = max(len(m.allTags)-1, 0)
+	}
+	return m, nil
+}
+
+func (m Model) selectedTag() (model.Tag, bool) {
+	if m.tagCursor < len(m.allTags) {
+		return m.allTags[m.tagCursor], true
+	}
+	return model.Tag{}, false
+}
+
+func (m Model) updateTagManager(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+
+	switch m.tagMgrMode {
+	case tagMgrRename:
+		if ok {
+			switch keyMsg.String() {
+			case "enter":
+				name := strings.TrimSpace(m.tagInput.Value())
+				if tag, found := m.selectedTag(); found && name != "" && name != tag.Name {
+					if err := m.store.RenameTag(tag.ID, name); err != nil {
+						m.err = err
+						return m, nil
+					}
+				}
+				m.tagMgrMode = tagMgrBrowse
+				m.tagInput.Reset()
+				return m.reloadTagManager()
+			case "esc":
+				m.tagMgrMode = tagMgrBrowse
+				m.tagInput.Reset()
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.tagInput, cmd = m.tagInput.Update(msg)
+		return m, cmd
+
+	case tagMgrRecolor:
+		if ok {
+			switch keyMsg.String() {
+			case "enter":
+				if tag, found := m.selectedTag(); found {
+					if err := m.store.SetTagColor(tag.ID, m.colorPicker.Value()); err != nil {
+						m.err = err
+						return m, nil
+					}
+				}
+				m.tagMgrMode = tagMgrBrowse
+				return m.reloadTagManager()
+			case "i":
+				// Nested tags can drop their own color to follow the parent again.
+				if tag, found := m.selectedTag(); found && tag.Depth() > 0 {
+					if err := m.store.SetTagColor(tag.ID, ""); err != nil {
+						m.err = err
+						return m, nil
+					}
+					m.tagMgrMode = tagMgrBrowse
+					return m.reloadTagManager()
+				}
+			case "esc":
+				m.tagMgrMode = tagMgrBrowse
+				return m, nil
+			}
+		}
+		var cmd tea.Cmd
+		m.colorPicker, cmd = m.colorPicker.Update(msg)
+		return m, cmd
+
+	case tagMgrDelete:
+		if ok {
+			switch keyMsg.String() {
+			case "y":
+				if tag, found := m.selectedTag(); found {
+					if err := m.store.DeleteTag(tag.ID); err != nil {
+						m.err = err
+					}
+				}
+				m.tagMgrMode = tagMgrBrowse
+				return m.reloadTagManager()
+			case "n", "esc":
+				m.tagMgrMode = tagMgrBrowse
+			}
+		}
+		return m, nil
+	}
+
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "j", "down":
+		if m.tagCursor < len(m.allTags)-1 {
+			m.tagCursor++
+		}
+	case "k", "up":
+		if m.tagCursor > 0 {
+			m.tagCursor--
+		}
+	case "r":
+		if tag, found := m.selectedTag(); found && m.tagMgrMode == tagMgrBrowse {
+			m.tagMgrMode = tagMgrRename
+			m.tagInput.Reset()
+			m.tagInput.SetValue(tag.Name)
+			cmd := m.tagInput.Focus()
+			return m, cmd
+		}
+	case "c":
+		if tag, found := m.selectedTag(); found && m.tagMgrMode == tagMgrBrowse {
+			m.tagMgrMode = tagMgrRecolor
+			m.colorPicker = newColorPicker(tag.Color)
+		}
+	case "d":
+		if _, found := m.selectedTag(); found && m.tagMgrMode == tagMgrBrowse {
+			m.tagMgrMode = tagMgrDelete
+		}
+	case "m":
+		if tag, found := m.selectedTag(); found && m.tagMgrMode == tagMgrBrowse {
+			m.tagMgrMode = tagMgrMerge
+			m.tagMergeSrc = tag
+		}
+	case "enter":
+		if tag, found := m.selectedTag(); found && m.tagMgrMode == tagMgrMerge {
+			if tag.ID != m.tagMergeSrc.ID {
+				if err := m.store.MergeTags(m.tagMergeSrc.ID, tag.ID); err != nil {
+					m.err = err
+				}
+			}
+			m.tagMgrMode = tagMgrBrowse
+			return m.reloadTagManager()
+		}
+	case "esc":
+		if m.tagMgrMode == tagMgrMerge {
+			m.tagMgrMode = tagMgrBrowse
+			return m, nil
+		}
+		m.state = stateList
+		return m, m.loadTasks
+	}
+	return m, nil
+}
+
+func (m Model) renderTagManager() string {
+	var lines []string
+	for i, tag := range m.allTags {
+		cursor := "  "
+		if i == m.tagCursor {
+			cursor = "> "
+		}
+		badge := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(tag.Color)).
+			Bold(true).
+			Render("[" + tag.Leaf() + "]")
+		count := m.tagUsage[tag.ID]
+		unit := m.text.UnitTasks
+		if count == 1 {
+			unit = m.text.UnitTask
+		}
+		color := tag.Color
+		if tag.Inherited {
+			color += m.text.Inherited
+		}
+		line := fmt.Sprintf("%s%s%s %s", cursor, tagIndent(tag), badge, statusStyle.Render(fmt.Sprintf(m.text.TagUsagef, count, unit, color)))
+		if m.tagMgrMode == tagMgrMerge && tag.ID == m.tagMergeSrc.ID {
+			line += m.text.Merging
+		}
+		lines = append(lines, line)
+	}
+	if len(lines) == 0 {
+		lines = append(lines, statusStyle.Render(m.text.NoTags))
+	}
+
+	content := titleStyle.Render(m.text.ManageTags) + "\n\n" + strings.Join(lines, "\n") + "\n\n"
+
+	tag, _ := m.selectedTag()
+	switch m.tagMgrMode {
+	case tagMgrRename:
+		content += m.tagInput.View() + "\n\n" +
+			statusStyle.Render(m.text.RenameHelp)
+	case tagMgrRecolor:
+		preview := lipgloss.NewStyle().
+			Foreground(lipgloss.Color(m.colorPicker.Value())).
+			Bold(true).
+			Render("[" + tag.Name + "]")
+		help := m.text.RecolorHelp
+		if tag.Depth() > 0 {
+			help = m.text.RecolorInheritHelp
+		}
+		content += m.colorPicker.View() + "\n\n" +
+			preview + " " + statusStyle.Render(m.colorPicker.Value()) + "\n\n" +
+			statusStyle.Render(help)
+	case tagMgrMerge:
+		content += confirmStyle.Render(fmt.Sprintf(m.text.MergeIntof, m.tagMergeSrc.Name)) + "\n\n" +
+			statusStyle.Render(m.text.MergeHelp)
+	case tagMgrDelete:
+		content += confirmStyle.Render(fmt.Sprintf(m.text.DeleteTagf, tag.Name, m.tagUsage[tag.ID])) + "\n\n" +
+			statusStyle.Render(m.text.DeleteHelp)
+	default:
+		content += statusStyle.Render(m.text.TagManagerHelp)
+	}
+	return content
+}
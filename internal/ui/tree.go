@@ -3,8 +3,8 @@ package ui
 import "github.com/nissyi-gh/flow/internal/model"
 
 // BuildTree converts a flat task list into a tree-ordered list of TaskItems
-// with tree-drawing prefixes (├─, └─, │).
-func BuildTree(tasks []model.Task) []TaskItem {
+// with tree-drawing prefixes (├─, └─, │). Siblings are ordered by mode.
+func BuildTree(tasks []model.Task, mode SortMode) []TaskItem {
 	children := make(map[int][]model.Task)
 	var roots []model.Task
 
@@ -16,6 +16,11 @@ func BuildTree(tasks []model.Task) []TaskItem {
 		}
 	}
 
+	sortSiblings(roots, mode)
+	for id := range children {
+		sortSiblings(children[id], mode)
+	}
+
 	var items []TaskItem
 	var dfs func(task model.Task, ancestors []bool)
 	dfs = func(task model.Task, ancestors []bool) {
@@ -1,6 +1,7 @@
 package ui
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -11,19 +12,14 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/nissyi-gh/flow/internal/config"
 	"github.com/nissyi-gh/flow/internal/importer"
+	"github.com/nissyi-gh/flow/internal/llm"
 	"github.com/nissyi-gh/flow/internal/model"
 	"github.com/nissyi-gh/flow/internal/prompt"
 	"github.com/nissyi-gh/flow/internal/store"
 )
 
-type viewMode int
-
-const (
-	viewAll viewMode = iota
-	viewToday
-)
-
 type appState int
 
 const (
@@ -37,6 +33,14 @@ const (
 	stateImportSelect
 	stateImportResult
 	stateQuitConfirm
+	stateViewSelect
+	stateTagFilter
+	stateTagManager
+	stateExport
+	stateImportPreview
+	stateLLMRequest
+	stateLLMStream
+	stateRewrite
 )
 
 var (
@@ -50,7 +54,6 @@ var (
 			BorderLeft(true).
 			BorderStyle(lipgloss.NormalBorder()).
 			BorderForeground(lipgloss.Color("39"))
-	tagColorPalette = []string{"39", "205", "148", "214", "141", "81", "203", "227"}
 )
 
 type extraKeyMap struct {
@@ -106,7 +109,7 @@ func newExtraKeyMap() extraKeyMap {
 		),
 		Import: key.NewBinding(
 			key.WithKeys("G"),
-			key.WithHelp("G", "import YAML"),
+			key.WithHelp("G", "import"),
 		),
 	}
 }
@@ -131,10 +134,37 @@ type Model struct {
 	tagInput       textinput.Model
 	genCursor       int
 	importCursor    int
-	importYAML      string
+	importTarget    importTarget // preselected when the preview is accepted
+	importPlan      *importer.ImportPlan
+	importFormat    importer.Format
+	previewCursor   int
 	importResult    string
 	importIsError   bool
-	viewMode       viewMode
+	exportCursor    int
+	rewrite         *importer.Rewrite
+	rewriteAccepted []bool
+	rewriteCursor   int
+	prompts         *prompt.Set
+	promptErr       error // from loading the user's prompt templates
+	genTemplate     prompt.Template
+	llm             *llm.Client // nil unless an endpoint is configured
+	llmInput        textinput.Model
+	llmCancel       context.CancelFunc
+	llmCh           <-chan tea.Msg
+	llmOutput       string
+	view           model.View
+	views          []model.View
+	viewCursor     int
+	viewStep       viewStep
+	viewInput      textinput.Model
+	viewDraftQuery string
+	tagFilter      tagFilter
+	tagMgrMode     tagMgrMode
+	tagUsage       map[int]int
+	tagMergeSrc    model.Tag
+	colorPicker    colorPicker
+	sortMode       SortMode
+	text           *catalog
 	err            error
 	width          int
 	height         int
@@ -144,9 +174,11 @@ type tasksLoadedMsg []model.Task
 type errMsg struct{ error }
 
 // NewModel creates a new TUI model.
-func NewModel(s *store.TaskStore) Model {
+func NewModel(s *store.TaskStore, cfg config.Config) Model {
+	text := catalogFor(cfg.Lang())
+
 	ti := textinput.New()
-	ti.Placeholder = "Task title..."
+	ti.Placeholder = text.TitlePlaceholder
 	ti.CharLimit = 256
 
 	keys := newExtraKeyMap()
@@ -168,35 +200,78 @@ func NewModel(s *store.TaskStore) Model {
 	l.Styles.Title = titleStyle
 	l.SetShowHelp(false)
 	l.SetFilteringEnabled(true)
-	l.SetStatusBarItemName("task", "tasks")
+	l.SetStatusBarItemName(text.ItemName, text.ItemsName)
+	l.FilterInput.Prompt = text.FilterPrompt
 
 	ta := textarea.New()
-	ta.Placeholder = "Task description..."
+	ta.Placeholder = text.DescPlaceholder
 	ta.CharLimit = 4096
 
 	tagIn := textinput.New()
-	tagIn.Placeholder = "New tag name..."
-	tagIn.CharLimit = 32
+	tagIn.Placeholder = text.TagPlaceholder
+	tagIn.CharLimit = 64
+
+	viewIn := textinput.New()
+	viewIn.CharLimit = 256
+
+	llmIn := textinput.New()
+	llmIn.Placeholder = text.RequestPlaceholder
+	llmIn.CharLimit = 1000
+
+	// A broken user template should not keep the TUI from starting; the
+	// error is shown in the AI menu, which falls back to the built-in prompts.
+	dir, promptErr := config.Dir()
+	var prompts *prompt.Set
+	if promptErr == nil {
+		prompts, promptErr = prompt.Load(cfg.Lang(), prompt.Dir(dir))
+	}
+	if promptErr != nil {
+		prompts, _ = prompt.Load(cfg.Lang(), "")
+	}
+
+	var client *llm.Client
+	if cfg.LLM.Enabled() {
+		client = llm.New(cfg.LLM)
+	}
+
+	// A missing or unreadable setting just means the default order and view.
+	sortName, _ := s.Setting("sort_mode")
+	viewName, _ := s.Setting("view")
 
 	return Model{
 		state:     stateList,
 		list:      l,
 		input:     ti,
-		dateInput: newDateInput(),
+		dateInput: newDateInput(text),
 		descInput: ta,
 		tagInput:  tagIn,
+		viewInput: viewIn,
+		prompts:   prompts,
+		promptErr: promptErr,
+		llmInput:  llmIn,
+		llm:       client,
 		store:     s,
 		keys:      keys,
+		sortMode:  ParseSortMode(sortName),
+		text:      text,
+		view:      resolveView(s, viewName),
+		tagFilter: newTagFilter(),
 	}
 }
 
 func (m Model) viewTitle() string {
-	switch m.viewMode {
-	case viewToday:
-		return "flow [📌 today]"
+	title := "flow"
+	switch {
+	case m.view.Query == "":
+	case m.view.ID == 0 && m.view.Name == "today":
+		title += " [📌 " + m.text.viewName(m.view) + "]"
 	default:
-		return "flow"
+		title += " [🔎 " + m.text.viewName(m.view) + "]"
+	}
+	if m.tagFilter.active() {
+		title += " [🏷 " + m.tagFilter.summary() + "]"
 	}
+	return title + " ↕ " + m.text.sortMode(m.sortMode)
 }
 
 func (m Model) Init() tea.Cmd {
@@ -208,7 +283,26 @@ func (m Model) loadTasks() tea.Msg {
 	if err != nil {
 		return errMsg{err}
 	}
-	return tasksLoadedMsg(tasks)
+	if m.view.Query == "" && !m.tagFilter.active() {
+		return tasksLoadedMsg(tasks)
+	}
+	var inView map[int]bool
+	if m.view.Query != "" {
+		matched, err := m.store.Search(m.view.Query)
+		if err != nil {
+			return errMsg{err}
+		}
+		inView = make(map[int]bool, len(matched))
+		for _, t := range matched {
+			inView[t.ID] = true
+		}
+	}
+	return tasksLoadedMsg(withAncestors(tasks, func(t model.Task) bool {
+		if inView != nil && !inView[t.ID] {
+			return false
+		}
+		return m.tagFilter.matches(t)
+	}))
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -228,38 +322,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case tasksLoadedMsg:
 		tasks := []model.Task(msg)
-		if m.viewMode == viewToday {
-			taskByID := make(map[int]model.Task)
-			for _, t := range tasks {
-				taskByID[t.ID] = t
-			}
-			include := make(map[int]bool)
-			for _, t := range tasks {
-				if t.IsToday() {
-					// Include the task and all its ancestors
-					for cur := &t; cur != nil; {
-						if include[cur.ID] {
-							break
-						}
-						include[cur.ID] = true
-						if cur.ParentID != nil {
-							p := taskByID[*cur.ParentID]
-							cur = &p
-						} else {
-							cur = nil
-						}
-					}
-				}
-			}
-			var filtered []model.Task
-			for _, t := range tasks {
-				if include[t.ID] {
-					filtered = append(filtered, t)
-				}
-			}
-			tasks = filtered
-		}
-		treeItems := BuildTree(tasks)
+		treeItems := BuildTree(tasks, m.sortMode)
 		items := make([]list.Item, len(treeItems))
 		for i, ti := range treeItems {
 			items[i] = ti
@@ -289,12 +352,28 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.updateTagSelect(msg)
 	case stateGenerate:
 		return m.updateGenerate(msg)
+	case stateLLMRequest:
+		return m.updateLLMRequest(msg)
+	case stateLLMStream:
+		return m.updateLLMStream(msg)
+	case stateRewrite:
+		return m.updateRewrite(msg)
 	case stateImportSelect:
 		return m.updateImportSelect(msg)
 	case stateImportResult:
 		return m.updateImportResult(msg)
 	case stateQuitConfirm:
 		return m.updateQuitConfirm(msg)
+	case stateViewSelect:
+		return m.updateViewSelect(msg)
+	case stateTagFilter:
+		return m.updateTagFilter(msg)
+	case stateTagManager:
+		return m.updateTagManager(msg)
+	case stateExport:
+		return m.updateExport(msg)
+	case stateImportPreview:
+		return m.updateImportPreview(msg)
 	}
 
 	return m, nil
@@ -307,10 +386,15 @@ func (m Model) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = stateQuitConfirm
 			return m, nil
 		case "v":
-			if m.viewMode == viewAll {
-				m.viewMode = viewToday
-			} else {
-				m.viewMode = viewAll
+			return m.openViewSelect()
+		case "f":
+			return m.openTagFilter()
+		case "M":
+			return m.openTagManager()
+		case "o":
+			m.sortMode = m.sortMode.Next()
+			if err := m.store.SetSetting("sort_mode", m.sortMode.String()); err != nil {
+				m.err = err
 			}
 			return m, m.loadTasks
 		case "a", "n":
@@ -356,7 +440,7 @@ func (m Model) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if item, ok := m.list.SelectedItem().(TaskItem); ok {
 				m.state = stateDueDate
 				m.dueDateTaskID = item.Task.ID
-				m.dateInput = newDateInput()
+				m.dateInput = newDateInput(m.text)
 				if item.Task.DueDate != nil {
 					m.dateInput.SetValue(*item.Task.DueDate)
 				}
@@ -404,22 +488,36 @@ func (m Model) updateList(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "G":
 			content, err := clipboard.ReadAll()
 			if err != nil {
-				m.importResult = fmt.Sprintf("クリップボードの読み取りに失敗しました: %v", err)
+				m.importResult = fmt.Sprintf(m.text.ClipboardReadFailedf, err)
+				m.importIsError = true
+				m.state = stateImportResult
+				return m, nil
+			}
+			m.importTarget = importAsRoots
+			return m.openImportPreview(importer.StripCodeBlock(content))
+		case "R":
+			if _, ok := m.list.SelectedItem().(TaskItem); !ok {
+				return m, nil
+			}
+			content, err := clipboard.ReadAll()
+			if err != nil {
+				m.importResult = fmt.Sprintf(m.text.ClipboardReadFailedf, err)
 				m.importIsError = true
 				m.state = stateImportResult
 				return m, nil
 			}
-			m.importYAML = stripCodeBlock(content)
-			m.importCursor = 0
-			m.state = stateImportSelect
+			return m.openRewrite(importer.StripCodeBlock(content))
+		case "E":
+			m.state = stateExport
+			m.exportCursor = 0
 			return m, nil
 		case "c":
 			md := m.tasksToMarkdown()
 			if err := clipboard.WriteAll(md); err != nil {
-				m.importResult = fmt.Sprintf("クリップボードへのコピーに失敗しました: %v", err)
+				m.importResult = fmt.Sprintf(m.text.ClipboardWriteFailedf, err)
 				m.importIsError = true
 			} else {
-				m.importResult = "タスク一覧をクリップボードにコピーしました"
+				m.importResult = m.text.CopiedTasks
 				m.importIsError = false
 			}
 			m.state = stateImportResult
@@ -487,8 +585,9 @@ func (m Model) updateEditDesc(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
-func nextTagColor(existingCount int) string {
-	return tagColorPalette[existingCount%len(tagColorPalette)]
+// tagIndent indents a nested tag under its parent in tag lists.
+func tagIndent(tag model.Tag) string {
+	return strings.Repeat("  ", tag.Depth())
 }
 
 func (m Model) updateTagSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -498,16 +597,17 @@ func (m Model) updateTagSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "enter":
 				name := strings.TrimSpace(m.tagInput.Value())
 				if name != "" {
-					tag, err := m.store.CreateTag(name, nextTagColor(len(m.allTags)))
+					tag, err := m.store.EnsureTag(name, model.PaletteColor(len(m.allTags)))
 					if err != nil {
 						m.err = err
+					} else if err := m.store.AssignTag(m.tagTaskID, tag.ID); err != nil {
+						m.err = err
 					} else {
-						m.allTags = append(m.allTags, tag)
-						if err := m.store.AssignTag(m.tagTaskID, tag.ID); err != nil {
-							m.err = err
-						} else {
-							m.assignedTags[tag.ID] = true
-						}
+						m.assignedTags[tag.ID] = true
+					}
+					// EnsureTag may also have created parent tags.
+					if allTags, err := m.store.ListTags(); err == nil {
+						m.allTags = allTags
 					}
 				}
 				m.tagCreating = false
@@ -564,67 +664,12 @@ func (m Model) updateTagSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) updateGenerate(msg tea.Msg) (tea.Model, tea.Cmd) {
-	if keyMsg, ok := msg.(tea.KeyMsg); ok {
-		switch keyMsg.String() {
-		case "j", "down":
-			if m.genCursor < 1 {
-				m.genCursor++
-			}
-		case "k", "up":
-			if m.genCursor > 0 {
-				m.genCursor--
-			}
-		case "enter":
-			if m.genCursor == 0 {
-				// New task breakdown
-				p := prompt.GenerateNew()
-				if err := clipboard.WriteAll(p); err != nil {
-					m.importResult = fmt.Sprintf("クリップボードへのコピーに失敗しました: %v", err)
-					m.importIsError = true
-				} else {
-					m.importResult = "新規タスク分解用のプロンプトをクリップボードにコピーしました"
-					m.importIsError = false
-				}
-				m.state = stateImportResult
-				return m, nil
-			} else {
-				// Improve existing task
-				item, ok := m.list.SelectedItem().(TaskItem)
-				if !ok {
-					m.state = stateList
-					return m, nil
-				}
-				children, err := m.store.ChildrenOf(item.Task.ID)
-				if err != nil {
-					m.err = err
-					m.state = stateList
-					return m, nil
-				}
-				p := prompt.GenerateFromTask(item.Task, children)
-				if err := clipboard.WriteAll(p); err != nil {
-					m.importResult = fmt.Sprintf("クリップボードへのコピーに失敗しました: %v", err)
-					m.importIsError = true
-				} else {
-					m.importResult = fmt.Sprintf("「%s」の分解プロンプトをクリップボードにコピーしました", item.Task.Title)
-					m.importIsError = false
-				}
-				m.state = stateImportResult
-				return m, nil
-			}
-		case "esc":
-			m.state = stateList
-			return m, nil
-		}
-	}
-	return m, nil
-}
-
 func (m Model) updateImportSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		targets := m.importTargets()
 		switch keyMsg.String() {
 		case "j", "down":
-			if m.importCursor < 1 {
+			if m.importCursor < len(targets)-1 {
 				m.importCursor++
 			}
 		case "k", "up":
@@ -632,16 +677,20 @@ func (m Model) updateImportSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.importCursor--
 			}
 		case "enter":
+			target := targets[m.importCursor]
 			var parentID *int
-			if m.importCursor == 1 {
+			if target == importAsChildren || target == mergeIntoChildren {
 				if item, ok := m.list.SelectedItem().(TaskItem); ok {
 					id := item.Task.ID
 					parentID = &id
 				}
 			}
+			if target == mergeIntoChildren || target == mergeIntoRoots {
+				return m.doMerge(parentID)
+			}
 			return m.doImport(parentID)
 		case "esc":
-			m.state = stateList
+			m.state = stateImportPreview
 			return m, nil
 		}
 	}
@@ -649,15 +698,34 @@ func (m Model) updateImportSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) doImport(parentID *int) (tea.Model, tea.Cmd) {
-	count, err := importer.Import(m.store, m.importYAML, parentID)
+	count, err := importer.Create(m.store, m.importPlan.Nodes(), parentID)
+	m.importPlan = nil
+	if err != nil {
+		m.importResult = fmt.Sprintf(m.text.ImportFailedf, m.importFormat, err)
+		m.importIsError = true
+		m.state = stateImportResult
+		return m, nil
+	}
+
+	m.importResult = fmt.Sprintf(m.text.Importedf, count)
+	m.importIsError = false
+	m.state = stateImportResult
+	return m, nil
+}
+
+// doMerge imports the previewed tasks under parentID, updating existing
+// tasks with the same title or id instead of adding duplicates.
+func (m Model) doMerge(parentID *int) (tea.Model, tea.Cmd) {
+	res, err := importer.Merge(m.store, m.importPlan.Nodes(), parentID)
+	m.importPlan = nil
 	if err != nil {
-		m.importResult = fmt.Sprintf("YAMLのインポートに失敗しました: %v", err)
+		m.importResult = fmt.Sprintf(m.text.MergeFailedf, m.importFormat, err)
 		m.importIsError = true
 		m.state = stateImportResult
 		return m, nil
 	}
 
-	m.importResult = fmt.Sprintf("✓ %d 件のタスクをインポートしました", count)
+	m.importResult = fmt.Sprintf(m.text.Mergedf, res.Created, res.Updated, res.Unchanged)
 	m.importIsError = false
 	m.state = stateImportResult
 	return m, nil
@@ -702,31 +770,6 @@ func (m Model) tasksToMarkdown() string {
 	return sb.String()
 }
 
-func stripCodeBlock(s string) string {
-	lines := strings.Split(s, "\n")
-	var result []string
-	inBlock := false
-	for _, line := range lines {
-		trimmed := strings.TrimSpace(line)
-		if !inBlock && (trimmed == "```yaml" || trimmed == "```yml" || trimmed == "```") {
-			inBlock = true
-			continue
-		}
-		if inBlock && trimmed == "```" {
-			inBlock = false
-			continue
-		}
-		if inBlock {
-			result = append(result, line)
-		}
-	}
-	// If no code block was found, return original
-	if len(result) == 0 {
-		return s
-	}
-	return strings.Join(result, "\n")
-}
-
 func (m Model) updateImportResult(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if _, ok := msg.(tea.KeyMsg); ok {
 		m.state = stateList
@@ -839,29 +882,49 @@ func (m Model) renderDetail() string {
 
 	// ## Description
 	sb.WriteString("\n\n")
-	sb.WriteString(sectionHeader.Render("Description"))
+	sb.WriteString(sectionHeader.Render(m.text.Description))
 	sb.WriteString("\n")
 	if item.Task.Description != nil && *item.Task.Description != "" {
 		sb.WriteString(*item.Task.Description)
 	} else {
-		sb.WriteString(statusStyle.Render("(no description)"))
+		sb.WriteString(statusStyle.Render(m.text.NoDescription))
 	}
 
 	// ## Property
 	sb.WriteString("\n\n")
-	sb.WriteString(sectionHeader.Render("Property"))
+	sb.WriteString(sectionHeader.Render(m.text.Properties))
 	sb.WriteString("\n")
 
+	// Align the values whatever the width of the labels.
+	labelWidth := 0
+	for _, label := range []string{m.text.DueDate, m.text.DependsOn, m.text.CreatedAt} {
+		labelWidth = max(labelWidth, lipgloss.Width(label))
+	}
+	property := func(label, value string) string {
+		return label + ":" + strings.Repeat(" ", labelWidth-lipgloss.Width(label)+2) + value
+	}
+
 	dueValue := statusStyle.Render("-")
 	if item.Task.DueDate != nil {
 		dueValue = *item.Task.DueDate
 	}
-	sb.WriteString(fmt.Sprintf("due_date:    %s\n", dueValue))
-	sb.WriteString(fmt.Sprintf("created_at:  %s", item.Task.CreatedAt.Format("2006-01-02 15:04")))
+	sb.WriteString(property(m.text.DueDate, dueValue) + "\n")
+	if deps, err := m.store.Dependencies(item.Task.ID); err == nil && len(deps) > 0 {
+		var refs []string
+		for _, id := range deps {
+			ref := fmt.Sprintf("#%d", id)
+			if dep, err := m.store.GetByID(id); err == nil {
+				ref += " " + dep.Title
+			}
+			refs = append(refs, ref)
+		}
+		sb.WriteString(property(m.text.DependsOn, strings.Join(refs, ", ")) + "\n")
+	}
+	sb.WriteString(property(m.text.CreatedAt, item.Task.CreatedAt.Format("2006-01-02 15:04")))
 
 	// Footer
 	sb.WriteString("\n\n")
-	sb.WriteString(statusStyle.Render("e: edit description  T: tags"))
+	sb.WriteString(statusStyle.Render(m.text.DetailHelp))
 
 	return sb.String()
 }
@@ -869,15 +932,9 @@ func (m Model) renderDetail() string {
 func (m Model) renderHelp(width int) string {
 	keyStyle := lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39"))
 
-	items := []struct{ key, desc string }{
-		{"a/n", "add"}, {"s", "sub-task"}, {"p", "progress"}, {"enter/x", "done"}, {"d", "delete"},
-		{"t", "today"}, {"D", "due date"}, {"e", "edit desc"}, {"T", "tags"},
-		{"c", "copy"}, {"v", "view"}, {"g", "AI prompt"}, {"G", "import YAML"}, {"/", "filter"}, {"q", "quit"},
-	}
-
 	var lines []string
 	line := ""
-	for _, it := range items {
+	for _, it := range m.text.Keys {
 		entry := keyStyle.Render(it.key) + " " + it.desc
 		sep := statusStyle.Render(" • ")
 		if line == "" {
@@ -901,39 +958,45 @@ func (m Model) renderHelp(width int) string {
 func (m Model) View() string {
 	var errView string
 	if m.err != nil {
-		errView = "\n" + errorStyle.Render("Error: "+m.err.Error()) + "\n"
+		errView = "\n" + errorStyle.Render(m.text.ErrorPrefix+m.err.Error()) + "\n"
 	}
 
 	switch m.state {
+	case stateViewSelect:
+		return appStyle.Render(m.renderViewSelect() + errView)
+	case stateTagFilter:
+		return appStyle.Render(m.renderTagFilter() + errView)
+	case stateTagManager:
+		return appStyle.Render(m.renderTagManager() + errView)
+	case stateExport:
+		return appStyle.Render(m.renderExport() + errView)
+	case stateImportPreview:
+		return appStyle.Render(m.renderImportPreview() + errView)
+	case stateLLMRequest:
+		return appStyle.Render(m.renderLLMRequest() + errView)
+	case stateLLMStream:
+		return appStyle.Render(m.renderLLMStream() + errView)
+	case stateRewrite:
+		return appStyle.Render(m.renderRewrite() + errView)
 	case stateGenerate:
-		options := []string{"新しいタスクを分解する", "既存タスクを改善する"}
-		var taskName string
-		if item, ok := m.list.SelectedItem().(TaskItem); ok {
-			taskName = item.Task.Title
-		}
-		if taskName != "" {
-			options[1] = fmt.Sprintf("既存タスクを改善する (%s)", taskName)
-		}
-
-		var lines []string
-		for i, opt := range options {
-			cursor := "  "
-			if i == m.genCursor {
-				cursor = "> "
-			}
-			lines = append(lines, cursor+opt)
-		}
-		content := titleStyle.Render("AI Task Breakdown") + "\n\n" +
-			strings.Join(lines, "\n") + "\n\n" +
-			statusStyle.Render("j/k: navigate  enter: select  esc: cancel")
-		return appStyle.Render(content + errView)
+		return appStyle.Render(m.renderGenerate() + errView)
 
 	case stateImportSelect:
-		options := []string{"ルートタスクとしてインポート"}
-		if item, ok := m.list.SelectedItem().(TaskItem); ok {
-			options = append(options, fmt.Sprintf("「%s」の子タスクとしてインポート", item.Task.Title))
-		} else {
-			options = append(options, "選択中タスクの子タスクとしてインポート (未選択)")
+		item, selected := m.list.SelectedItem().(TaskItem)
+		var options []string
+		for _, target := range m.importTargets() {
+			switch {
+			case target == importAsRoots:
+				options = append(options, m.text.ImportAsRoots)
+			case target == importAsChildren && selected:
+				options = append(options, fmt.Sprintf(m.text.ImportAsChildrenOff, item.Task.Title))
+			case target == importAsChildren:
+				options = append(options, m.text.ImportAsChildrenNone)
+			case target == mergeIntoChildren:
+				options = append(options, fmt.Sprintf(m.text.MergeIntoChildrenOff, item.Task.Title))
+			case target == mergeIntoRoots:
+				options = append(options, m.text.MergeIntoRoots)
+			}
 		}
 
 		var lines []string
@@ -944,9 +1007,9 @@ func (m Model) View() string {
 			}
 			lines = append(lines, cursor+opt)
 		}
-		content := titleStyle.Render("Import YAML") + "\n\n" +
+		content := titleStyle.Render(fmt.Sprintf(m.text.ImportTitlef, m.importFormat)) + "\n\n" +
 			strings.Join(lines, "\n") + "\n\n" +
-			statusStyle.Render("j/k: navigate  enter: select  esc: cancel")
+			statusStyle.Render(m.text.ImportSelectHelp)
 		return appStyle.Render(content + errView)
 
 	case stateImportResult:
@@ -959,7 +1022,7 @@ func (m Model) View() string {
 			icon = ""
 		}
 		content := style.Render(icon+m.importResult) + "\n\n" +
-			statusStyle.Render("press any key to continue")
+			statusStyle.Render(m.text.PressAnyKey)
 		return appStyle.Render(content + errView)
 
 	case stateTagSelect:
@@ -975,54 +1038,54 @@ func (m Model) View() string {
 			}
 			badge := lipgloss.NewStyle().
 				Foreground(lipgloss.Color(tag.Color)).
-				Render(tag.Name)
-			lines = append(lines, cursor+check+" "+badge)
+				Render(tag.Leaf())
+			lines = append(lines, cursor+check+" "+tagIndent(tag)+badge)
 		}
 		newCursor := "  "
 		if m.tagCursor == len(m.allTags) {
 			newCursor = "> "
 		}
-		lines = append(lines, newCursor+"+ New tag...")
+		lines = append(lines, newCursor+m.text.NewTagItem)
 
-		content := titleStyle.Render("Tags") + "\n\n" +
+		content := titleStyle.Render(m.text.Tags) + "\n\n" +
 			strings.Join(lines, "\n")
 
 		if m.tagCreating {
 			content += "\n\n" + m.tagInput.View()
 		}
 
-		content += "\n\n" + statusStyle.Render("j/k: navigate  enter/space: toggle  esc: done")
+		content += "\n\n" + statusStyle.Render(m.text.TagSelectHelp)
 
 		return appStyle.Render(content + errView)
 	case stateEditDesc:
 		return appStyle.Render(
-			titleStyle.Render("Edit Description") + "\n\n" +
+			titleStyle.Render(m.text.EditDesc) + "\n\n" +
 				m.descInput.View() + "\n\n" +
-				statusStyle.Render("esc: save • ctrl+c: cancel") +
+				statusStyle.Render(m.text.EditDescHelp) +
 				errView,
 		)
 	case stateAdd:
-		header := "New Task"
+		header := m.text.NewTask
 		if m.addParentID != nil {
-			header = "New Sub-task"
+			header = m.text.NewSubTask
 		}
 		return appStyle.Render(
 			titleStyle.Render(header) + "\n\n" +
 				m.input.View() + "\n\n" +
-				statusStyle.Render("enter: save • esc: cancel") +
+				statusStyle.Render(m.text.AddHelp) +
 				errView,
 		)
 	case stateDueDate:
 		return appStyle.Render(
-			titleStyle.Render("Set Due Date") + "\n\n" +
+			titleStyle.Render(m.text.SetDueDate) + "\n\n" +
 				m.dateInput.View() + "\n\n" +
-				statusStyle.Render("tab/→: next field • enter: save • esc: cancel") +
+				statusStyle.Render(m.text.DueDateHelp) +
 				errView,
 		)
 	case stateQuitConfirm:
 		return appStyle.Render(
-			confirmStyle.Render("Quit flow?") + "\n\n" +
-				statusStyle.Render("y: quit • n/esc: cancel") +
+			confirmStyle.Render(m.text.QuitConfirm) + "\n\n" +
+				statusStyle.Render(m.text.QuitHelp) +
 				errView,
 		)
 	case stateConfirm:
@@ -1030,12 +1093,12 @@ func (m Model) View() string {
 		msg := item.Task.Title
 		hasChildren, _ := m.store.HasChildren(item.Task.ID)
 		if hasChildren {
-			msg = fmt.Sprintf("%s\n  (子タスクも削除されます)", item.Task.Title)
+			msg = item.Task.Title + "\n  " + m.text.DeleteChildren
 		}
 		return appStyle.Render(
-			confirmStyle.Render("Delete Task?") + "\n\n" +
+			confirmStyle.Render(m.text.DeleteConfirm) + "\n\n" +
 				"  " + msg + "\n\n" +
-				statusStyle.Render("y: delete • n/esc: cancel") +
+				statusStyle.Render(m.text.DeleteHelp) +
 				errView,
 		)
 	default:
@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nissyi-gh/flow/internal/model"
+	"github.com/nissyi-gh/flow/internal/store"
+)
+
+// builtinViews are always offered in the view picker and cannot be deleted.
+var builtinViews = []model.View{
+	{Name: "all"},
+	{Name: "today", Query: "scheduled:today"},
+}
+
+type viewStep int
+
+const (
+	viewStepNone viewStep = iota
+	viewStepQuery
+	viewStepName
+)
+
+// resolveView finds a built-in or saved view by name, falling back to "all".
+func resolveView(s *store.TaskStore, name string) model.View {
+	for _, v := range builtinViews {
+		if v.Name == name {
+			return v
+		}
+	}
+	saved, err := s.ListViews()
+	if err == nil {
+		for _, v := range saved {
+			if v.Name == name {
+				return v
+			}
+		}
+	}
+	return builtinViews[0]
+}
+
+// withAncestors returns the tasks matching include together with all of
+// their ancestors, preserving the original order.
+func withAncestors(tasks []model.Task, include func(model.Task) bool) []model.Task {
+	taskByID := make(map[int]model.Task)
+	for _, t := range tasks {
+		taskByID[t.ID] = t
+	}
+	keep := make(map[int]bool)
+	for _, t := range tasks {
+		if !include(t) {
+			continue
+		}
+		cur := t
+		for !keep[cur.ID] {
+			keep[cur.ID] = true
+			if cur.ParentID == nil {
+				break
+			}
+			parent, ok := taskByID[*cur.ParentID]
+			if !ok {
+				break
+			}
+			cur = parent
+		}
+	}
+	var filtered []model.Task
+	for _, t := range tasks {
+		if keep[t.ID] {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+func (m Model) openViewSelect() (tea.Model, tea.Cmd) {
+	saved, err := m.store.ListViews()
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.views = append(append([]model.View{}, builtinViews...), saved...)
+	m.viewCursor = 0
+	for i, v := range m.views {
+		if v.Name == m.view.Name {
+			m.viewCursor = i
+		}
+	}
+	m.viewStep = viewStepNone
+	m.state = stateViewSelect
+	return m, nil
+}
+
+func (m Model) updateViewSelect(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.viewStep != viewStepNone {
+		return m.updateViewCreate(msg)
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "j", "down":
+			if m.viewCursor < len(m.views) {
+				m.viewCursor++
+			}
+		case "k", "up":
+			if m.viewCursor > 0 {
+				m.viewCursor--
+			}
+		case "enter":
+			if m.viewCursor < len(m.views) {
+				m.view = m.views[m.viewCursor]
+				if err := m.store.SetSetting("view", m.view.Name); err != nil {
+					m.err = err
+				}
+				m.state = stateList
+				return m, m.loadTasks
+			}
+			m.viewStep = viewStepQuery
+			m.viewInput.Reset()
+			m.viewInput.Placeholder = "tag:work status:open due<=+7d"
+			cmd := m.viewInput.Focus()
+			return m, cmd
+		case "d":
+			if m.viewCursor < len(m.views) && m.views[m.viewCursor].ID != 0 {
+				v := m.views[m.viewCursor]
+				if err := m.store.DeleteView(v.ID); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.views = append(m.views[:m.viewCursor], m.views[m.viewCursor+1:]...)
+				if m.view.ID == v.ID {
+					m.view = builtinViews[0]
+					if err := m.store.SetSetting("view", m.view.Name); err != nil {
+						m.err = err
+					}
+				}
+			}
+		case "esc":
+			m.state = stateList
+			return m, m.loadTasks
+		}
+	}
+	return m, nil
+}
+
+func (m Model) updateViewCreate(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "enter":
+			val := strings.TrimSpace(m.viewInput.Value())
+			if m.viewStep == viewStepQuery {
+				if _, _, err := store.ParseQuery(val); err != nil {
+					m.err = err
+					return m, nil
+				}
+				m.err = nil
+				m.viewDraftQuery = val
+				m.viewStep = viewStepName
+				m.viewInput.Reset()
+				m.viewInput.Placeholder = m.text.ViewNamePlaceholder
+				return m, nil
+			}
+			if val == "" {
+				return m, nil
+			}
+			for _, v := range builtinViews {
+				if v.Name == val {
+					m.err = fmt.Errorf(m.text.ViewReservedf, val)
+					return m, nil
+				}
+			}
+			v, err := m.store.SaveView(val, m.viewDraftQuery)
+			if err != nil {
+				m.err = err
+				return m, nil
+			}
+			m.view = v
+			if err := m.store.SetSetting("view", v.Name); err != nil {
+				m.err = err
+			}
+			m.viewStep = viewStepNone
+			m.state = stateList
+			return m, m.loadTasks
+		case "esc":
+			m.viewStep = viewStepNone
+			m.viewInput.Reset()
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.viewInput, cmd = m.viewInput.Update(msg)
+	return m, cmd
+}
+
+func (m Model) renderViewSelect() string {
+	var lines []string
+	for i, v := range m.views {
+		cursor := "  "
+		if i == m.viewCursor {
+			cursor = "> "
+		}
+		line := cursor + m.text.viewName(v)
+		if v.Query != "" {
+			line += "  " + statusStyle.Render(v.Query)
+		}
+		if v.Name == m.view.Name {
+			line += " ✓"
+		}
+		lines = append(lines, line)
+	}
+	newCursor := "  "
+	if m.viewCursor == len(m.views) {
+		newCursor = "> "
+	}
+	lines = append(lines, newCursor+m.text.NewView)
+
+	content := titleStyle.Render(m.text.Views) + "\n\n" + strings.Join(lines, "\n")
+
+	switch m.viewStep {
+	case viewStepQuery:
+		content += "\n\n" + m.text.QueryLabel + m.viewInput.View()
+	case viewStepName:
+		content += "\n\n" + statusStyle.Render(m.viewDraftQuery) + "\n" + m.text.NameLabel + m.viewInput.View()
+	}
+
+	content += "\n\n" + statusStyle.Render(m.text.ViewsHelp)
+	return content
+}
@@ -5,6 +5,8 @@ import (
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/nissyi-gh/flow/internal/cli"
+	"github.com/nissyi-gh/flow/internal/config"
 	"github.com/nissyi-gh/flow/internal/store"
 	"github.com/nissyi-gh/flow/internal/ui"
 )
@@ -17,7 +19,19 @@ func main() {
 	}
 	defer s.Close()
 
-	p := tea.NewProgram(ui.NewModel(s), tea.WithAltScreen())
+	if len(os.Args) > 1 {
+		code := cli.Run(s, os.Args[1:], os.Stdout, os.Stderr)
+		s.Close()
+		os.Exit(code)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	p := tea.NewProgram(ui.NewModel(s, cfg), tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)